@@ -6,27 +6,122 @@
 package csv
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
+// Quoting tells a Writer when to enclose fields in quotes. *Quoting
+// implements flag.Value.
+type Quoting int
+
 // Values Dialect.Quoting can take.
 const (
-	QuoteDefault    = iota // See DefaultQuoting.
-	QuoteAll        = iota // Quotes around every field.
-	QuoteMinimal    = iota // Quotes when needed.
-	QuoteNonNumeric = iota // Quotes around non-numeric fields.
+	QuoteDefault    Quoting = iota // See DefaultQuoting.
+	QuoteAll                       // Quotes around every field.
+	QuoteMinimal                   // Quotes when needed.
+	QuoteNonNumeric                // Quotes around non-numeric fields.
 
 	// Never quote. Use with care. Could make things unparsable.
-	QuoteNone = iota
+	QuoteNone
 )
 
+var quotingNames = [...]string{
+	QuoteDefault:    "default",
+	QuoteAll:        "all",
+	QuoteMinimal:    "minimal",
+	QuoteNonNumeric: "nonnumeric",
+	QuoteNone:       "none",
+}
+
+// String returns the name of q, such as "minimal".
+func (q Quoting) String() string {
+	if q.valid() {
+		return quotingNames[q]
+	}
+	return fmt.Sprintf("Quoting(%d)", int(q))
+}
+
+func (q Quoting) valid() bool {
+	return q >= 0 && int(q) < len(quotingNames)
+}
+
+// ParseQuoting parses the name of a quoting mode, as returned by String.
+// "non-numeric" is accepted as well.
+func ParseQuoting(s string) (Quoting, error) {
+	if strings.EqualFold(s, "non-numeric") {
+		return QuoteNonNumeric, nil
+	}
+	for q, name := range quotingNames {
+		if strings.EqualFold(s, name) {
+			return Quoting(q), nil
+		}
+	}
+	return QuoteDefault, fmt.Errorf("csv: unknown quoting %q, expected one of %s", s, strings.Join(quotingNames[1:], ", "))
+}
+
+// Set implements flag.Value using ParseQuoting.
+func (q *Quoting) Set(s string) (err error) {
+	*q, err = ParseQuoting(s)
+	return err
+}
+
+// DoubleQuoteMode tells how quotes inside quoted fields are escaped.
+// *DoubleQuoteMode implements flag.Value.
+type DoubleQuoteMode int
+
 // Values Dialect.DoubleQuote can take.
 const (
-	DoubleQuoteDefault = iota // See DefaultDoubleQuote.
-	DoDoubleQuote      = iota // Escape using double escape characters.
-	NoDoubleQuote      = iota // Escape using escape character.
+	DoubleQuoteDefault DoubleQuoteMode = iota // See DefaultDoubleQuote.
+	DoDoubleQuote                             // Escape using double escape characters.
+	NoDoubleQuote                             // Escape using escape character.
 )
 
+var doubleQuoteNames = [...]string{
+	DoubleQuoteDefault: "default",
+	DoDoubleQuote:      "double",
+	NoDoubleQuote:      "escape",
+}
+
+// String returns the name of m, such as "double".
+func (m DoubleQuoteMode) String() string {
+	if m.valid() {
+		return doubleQuoteNames[m]
+	}
+	return fmt.Sprintf("DoubleQuoteMode(%d)", int(m))
+}
+
+func (m DoubleQuoteMode) valid() bool {
+	return m >= 0 && int(m) < len(doubleQuoteNames)
+}
+
+// ParseDoubleQuoteMode parses the name of a mode, as returned by String.
+// Booleans such as "true" and "false" are accepted as well, for DoDoubleQuote
+// and NoDoubleQuote.
+func ParseDoubleQuoteMode(s string) (DoubleQuoteMode, error) {
+	for m, name := range doubleQuoteNames {
+		if strings.EqualFold(s, name) {
+			return DoubleQuoteMode(m), nil
+		}
+	}
+	if double, err := strconv.ParseBool(s); err == nil {
+		if double {
+			return DoDoubleQuote, nil
+		}
+		return NoDoubleQuote, nil
+	}
+	return DoubleQuoteDefault, fmt.Errorf("csv: unknown double quote mode %q, expected one of %s", s, strings.Join(doubleQuoteNames[1:], ", "))
+}
+
+// Set implements flag.Value using ParseDoubleQuoteMode.
+func (m *DoubleQuoteMode) Set(s string) (err error) {
+	*m, err = ParseDoubleQuoteMode(s)
+	return err
+}
+
 // Default dialect.
 const (
 	DefaultDelimiter      = ' '
@@ -37,6 +132,20 @@ const (
 	DefaultLineTerminator = "\n"
 )
 
+// NoChar can be used as Dialect.QuoteChar or Dialect.EscapeChar to disable
+// quoting or escaping altogether, rather than getting the default. A dialect
+// without a quote character defaults to QuoteNone.
+const NoChar rune = -1
+
+// LineTerminatorAny can be used as Dialect.LineTerminator to make a Reader
+// accept "\r\n", "\n" and "\r" alike, much like Python's universal newlines.
+// Reader.LineTerminator reports which one predominated. A Writer writes
+// DefaultLineTerminator and quotes fields containing '\r' or '\n'.
+const LineTerminatorAny = "\r\n|\n|\r"
+
+// Line terminators accepted by LineTerminatorAny, in the order they are tried.
+var universalLineTerminators = [...]string{"\r\n", "\n", "\r"}
+
 // A Dialect specifies the format of a CSV file. This structure is used by a
 // Reader or Writer to know how to operate on the file they are
 // reading/writing.
@@ -45,26 +154,74 @@ type Dialect struct {
 	// DefaultDelimiter.
 	Delimiter rune
 	// What quoting mode to use. Defaults to DefaultQuoting.
-	Quoting int
+	Quoting Quoting
 	// How to escape quotes. Defaults to DefaultDoubleQuote.
-	DoubleQuote int
+	DoubleQuote DoubleQuoteMode
 	// Character to use for escaping. Only used if DoubleQuote==NoDoubleQuote.
-	// Defaults to DefaultEscapeChar.
+	// Defaults to DefaultEscapeChar. NoChar means there is none.
 	EscapeChar rune
 	// Character to use as quotation mark around quoted fields. Defaults to
-	// DefaultQuoteChar.
+	// DefaultQuoteChar. NoChar means fields are never quoted.
 	QuoteChar rune
 	// String that separates each record in a CSV file. Defaults to
-	// DefaultLineTerminator.
+	// DefaultLineTerminator. See also LineTerminatorAny.
 	LineTerminator string
+
+	// String that separates each field from another. Takes precedence over
+	// Delimiter and makes multi-character delimiters such as "||" possible.
+	// Defaults to Delimiter.
+	DelimiterString string
+	// String to use as opening quotation mark around quoted fields. Takes
+	// precedence over QuoteChar. Defaults to QuoteChar.
+	QuoteString string
+	// String to use as closing quotation mark around quoted fields. Makes
+	// enclosures such as "<<" and ">>" possible. Defaults to QuoteString.
+	CloseQuoteString string
+
+	// Whether to ignore spaces directly following a delimiter when reading.
+	SkipInitialSpace bool
+	// Character encoding of the file, such as EncodingWindows1252. A Reader
+	// converts it to UTF-8, skipping any byte order mark, and a Writer
+	// converts UTF-8 to it. Data is used as is if empty.
+	Encoding string
+	// Lines starting with this character are skipped when reading. Zero
+	// means there are no comments.
+	Comment rune
+	// Whether the first record is a header. A Reader reads it before the
+	// first record and makes it available through Reader.Header.
+	Header bool
+}
+
+// SetDelimiter sets Delimiter if s is a single character and DelimiterString
+// otherwise, clearing the other one.
+func (d *Dialect) SetDelimiter(s string) {
+	d.Delimiter, d.DelimiterString = splitString(s)
+}
+
+// SetQuote sets QuoteChar if s is a single character and QuoteString
+// otherwise, clearing the other one. An empty s gives NoChar.
+func (d *Dialect) SetQuote(s string) {
+	d.QuoteChar, d.QuoteString = splitQuote(s)
 }
 
 func (wo *Dialect) setDefaults() {
+	if wo.DelimiterString != "" {
+		wo.Delimiter, _ = utf8.DecodeRuneInString(wo.DelimiterString)
+	}
 	if wo.Delimiter == 0 {
 		wo.Delimiter = DefaultDelimiter
 	}
+	if wo.DelimiterString == "" {
+		wo.DelimiterString = string(wo.Delimiter)
+	}
+	if wo.QuoteString != "" {
+		wo.QuoteChar, _ = utf8.DecodeRuneInString(wo.QuoteString)
+	}
 	if wo.Quoting == QuoteDefault {
 		wo.Quoting = DefaultQuoting
+		if wo.QuoteChar == NoChar {
+			wo.Quoting = QuoteNone
+		}
 	}
 	if wo.LineTerminator == "" {
 		wo.LineTerminator = DefaultLineTerminator
@@ -75,11 +232,94 @@ func (wo *Dialect) setDefaults() {
 	if wo.QuoteChar == 0 {
 		wo.QuoteChar = DefaultQuoteChar
 	}
+	if wo.QuoteString == "" && wo.QuoteChar != NoChar {
+		wo.QuoteString = string(wo.QuoteChar)
+	}
+	if wo.CloseQuoteString == "" {
+		wo.CloseQuoteString = wo.QuoteString
+	}
 	if wo.EscapeChar == 0 {
 		wo.EscapeChar = DefaultEscapeChar
 	}
 }
 
+// ErrInvalidDialect is wrapped by the errors returned by Dialect.Validate.
+var ErrInvalidDialect = errors.New("csv: invalid dialect")
+
+// Validate checks that the dialect, with defaults applied, can be read and
+// written unambiguously: that enum fields hold known values, that the
+// delimiter, quotes and line terminator don't overlap, that the characters
+// used are valid and that the encoding is supported.
+func (d Dialect) Validate() error {
+	d.setDefaults()
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidDialect, fmt.Sprintf(format, args...))
+	}
+
+	if !d.Quoting.valid() {
+		return invalid("unknown quoting %v", d.Quoting)
+	}
+	if !d.DoubleQuote.valid() {
+		return invalid("unknown double quote mode %v", d.DoubleQuote)
+	}
+	if d.Delimiter == NoChar || d.Comment == NoChar {
+		return invalid("only the quote and escape characters can be NoChar")
+	}
+	escape := ""
+	if d.EscapeChar != NoChar {
+		escape = string(d.EscapeChar)
+	}
+	for _, s := range []string{d.DelimiterString, d.QuoteString, d.CloseQuoteString, escape, d.LineTerminator} {
+		if !utf8.ValidString(s) || strings.ContainsRune(s, utf8.RuneError) {
+			return invalid("%q is not valid UTF-8", s)
+		}
+	}
+
+	terminators := []string{d.LineTerminator}
+	if d.LineTerminator == LineTerminatorAny {
+		terminators = []string{"\r", "\n"}
+	}
+	for _, field := range []struct{ name, value string }{
+		{"delimiter", d.DelimiterString},
+		{"quote", d.QuoteString},
+		{"closing quote", d.CloseQuoteString},
+	} {
+		if field.value == "" {
+			continue
+		}
+		for _, terminator := range terminators {
+			if strings.Contains(field.value, terminator) || strings.Contains(terminator, field.value) {
+				return invalid("%s %q overlaps line terminator %q", field.name, field.value, terminator)
+			}
+		}
+	}
+	if d.QuoteString == "" {
+		if d.Quoting != QuoteNone {
+			return invalid("quoting %v needs a quote character", d.Quoting)
+		}
+		if d.CloseQuoteString != "" {
+			return invalid("closing quote %q without a quote", d.CloseQuoteString)
+		}
+	} else if strings.HasPrefix(d.QuoteString, d.DelimiterString) || strings.HasPrefix(d.DelimiterString, d.QuoteString) {
+		return invalid("delimiter %q overlaps quote %q", d.DelimiterString, d.QuoteString)
+	}
+	if d.EscapeChar == NoChar && d.DoubleQuote == NoDoubleQuote && d.Quoting != QuoteNone {
+		return invalid("quotes can neither be doubled nor escaped")
+	}
+	if d.DoubleQuote == NoDoubleQuote && strings.ContainsRune(d.DelimiterString, d.EscapeChar) {
+		return invalid("delimiter %q contains escape character %q", d.DelimiterString, d.EscapeChar)
+	}
+	if d.Comment != 0 && (d.Comment == d.Delimiter || d.Comment == d.QuoteChar) {
+		return invalid("comment character %q is also used as delimiter or quote", d.Comment)
+	}
+	if d.Encoding != "" {
+		if _, err := lookupEncoding(d.Encoding); err != nil {
+			return invalid("unknown encoding %q", d.Encoding)
+		}
+	}
+	return nil
+}
+
 func isNumeric(s string) bool {
 	if len(s) == 0 {
 		return false
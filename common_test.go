@@ -4,6 +4,8 @@
 package csv
 
 import (
+	"errors"
+	"flag"
 	"testing"
 )
 
@@ -33,3 +35,112 @@ func TestIsNumeric(t *testing.T) {
 		}
 	}
 }
+
+func TestQuoting(t *testing.T) {
+	t.Parallel()
+
+	for _, q := range []Quoting{QuoteDefault, QuoteAll, QuoteMinimal, QuoteNonNumeric, QuoteNone} {
+		parsed, err := ParseQuoting(q.String())
+		if err != nil || parsed != q {
+			t.Error("Unexpected round trip:", q, parsed, err)
+		}
+	}
+	if s := Quoting(42).String(); s != "Quoting(42)" {
+		t.Error("Unexpected name:", s)
+	}
+	if q, err := ParseQuoting("Non-Numeric"); err != nil || q != QuoteNonNumeric {
+		t.Error("Unexpected quoting:", q, err)
+	}
+	if _, err := ParseQuoting("sometimes"); err == nil {
+		t.Error("Expected error")
+	}
+
+	// Usable as flags.
+	var d Dialect
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.Var(&d.Quoting, "quoting", "")
+	f.Var(&d.DoubleQuote, "double-quote", "")
+	if err := f.Parse([]string{"-quoting", "all", "-double-quote", "false"}); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if d.Quoting != QuoteAll || d.DoubleQuote != NoDoubleQuote {
+		t.Errorf("Unexpected dialect: %#v", d)
+	}
+}
+
+func TestDoubleQuoteMode(t *testing.T) {
+	t.Parallel()
+
+	for _, m := range []DoubleQuoteMode{DoubleQuoteDefault, DoDoubleQuote, NoDoubleQuote} {
+		parsed, err := ParseDoubleQuoteMode(m.String())
+		if err != nil || parsed != m {
+			t.Error("Unexpected round trip:", m, parsed, err)
+		}
+	}
+	if m, err := ParseDoubleQuoteMode("true"); err != nil || m != DoDoubleQuote {
+		t.Error("Unexpected mode:", m, err)
+	}
+	if _, err := ParseDoubleQuoteMode("sometimes"); err == nil {
+		t.Error("Expected error")
+	}
+}
+
+func TestDialectSetters(t *testing.T) {
+	t.Parallel()
+
+	d := Dialect{Delimiter: ',', DelimiterString: "||", QuoteString: "<<"}
+	d.SetDelimiter(";")
+	d.SetQuote("'")
+	if d.Delimiter != ';' || d.DelimiterString != "" || d.QuoteChar != '\'' || d.QuoteString != "" {
+		t.Errorf("Unexpected dialect: %#v", d)
+	}
+
+	d.SetDelimiter("||")
+	d.SetQuote("")
+	if d.Delimiter != 0 || d.DelimiterString != "||" || d.QuoteChar != NoChar || d.QuoteString != "" {
+		t.Errorf("Unexpected dialect: %#v", d)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := []Dialect{
+		{},
+		{Delimiter: ',', DoubleQuote: DoDoubleQuote, LineTerminator: "\r\n"},
+		{DelimiterString: "||", QuoteString: "<<", CloseQuoteString: ">>", LineTerminator: LineTerminatorAny},
+		{Delimiter: '\t', Comment: '#', Encoding: EncodingLatin1},
+		{Delimiter: '\t', QuoteChar: NoChar, EscapeChar: NoChar},
+		{QuoteChar: NoChar, Quoting: QuoteNone, DoubleQuote: NoDoubleQuote},
+		{EscapeChar: NoChar, Quoting: QuoteAll},
+	}
+	for _, d := range valid {
+		if err := d.Validate(); err != nil {
+			t.Error("Unexpected error:", err)
+		}
+	}
+
+	invalid := []Dialect{
+		{Quoting: 42},
+		{DoubleQuote: -1},
+		{Delimiter: '\n'},
+		{Delimiter: '\r', LineTerminator: LineTerminatorAny},
+		{LineTerminator: ";", DelimiterString: ";;"},
+		{Delimiter: '"'},
+		{QuoteString: "''", DelimiterString: "'"},
+		{Delimiter: '\\', DoubleQuote: NoDoubleQuote},
+		{Delimiter: ',', Comment: ','},
+		{Encoding: "ebcdic"},
+		{DelimiterString: "\xff"},
+		{QuoteChar: NoChar, Quoting: QuoteMinimal},
+		{QuoteChar: NoChar, CloseQuoteString: ">>"},
+		{EscapeChar: NoChar, DoubleQuote: NoDoubleQuote},
+		{Delimiter: NoChar},
+		{Comment: NoChar},
+	}
+	for _, d := range invalid {
+		if err := d.Validate(); !errors.Is(err, ErrInvalidDialect) {
+			t.Errorf("Unexpected error for %#v: %v", d, err)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package detector
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Delimiters preferred when candidates score equally, most preferred first,
+// unless candidates are configured using NewWithCandidates.
+const preferredDelimiters = ",\t;|: "
+
+// DelimiterCandidate is a potential delimiter along with how well it splits
+// the sampled rows.
+type DelimiterCandidate struct {
+	Delimiter string
+	// Overall score from 0 to 1 that candidates are ranked by.
+	Score float64
+	// Fraction of rows containing the most common number of delimiters.
+	Consistency float64
+	// Variance of the number of delimiters per row. Zero if every row has the
+	// same number of fields.
+	FieldCountVariance float64
+	// Fraction of enclosure characters directly next to the delimiter or a
+	// row boundary. One if there are no enclosures.
+	QuoteBalance float64
+	// Average number of delimiters per row.
+	Frequency float64
+}
+
+// A Ranker is a Detector that also scores the delimiters it considers.
+type Ranker interface {
+	Detector
+	RankDelimiters(reader io.Reader, enclosure byte) []DelimiterCandidate
+}
+
+// NewWithCandidates creates a Ranker that only considers the given
+// delimiters, which may be several characters long. Without any, every
+// character in the sample that isn't alphanumeric is a candidate, like for
+// New.
+func NewWithCandidates(candidates ...string) Ranker {
+	d := newDetector()
+	d.candidates = candidates
+	return d
+}
+
+// RankDelimiters scores every candidate delimiter found in r, best first.
+// Delimiters inside fields enclosed by enclosure are not counted. Candidates
+// not found in every row are left out.
+func (d *detector) RankDelimiters(r io.Reader, enclosure byte) []DelimiterCandidate {
+	b, _ := ioutil.ReadAll(r)
+	return d.rankDelimiters(b, enclosure)
+}
+
+func (d *detector) rankDelimiters(sample []byte, enclosure byte) []DelimiterCandidate {
+	rows := splitRows(sample, enclosure)
+	if len(rows) == 0 {
+		return []DelimiterCandidate{}
+	}
+
+	var ranked []DelimiterCandidate
+	for _, delimiter := range d.delimiterCandidates(sample, enclosure) {
+		c := scoreDelimiter(rows, []byte(delimiter), enclosure)
+		if c.Score > 0 {
+			ranked = append(ranked, c)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return d.preference(ranked[i].Delimiter) < d.preference(ranked[j].Delimiter)
+	})
+	return ranked
+}
+
+// delimiterCandidates returns the configured candidates or, if there are
+// none, the potential delimiters found in sample.
+func (d *detector) delimiterCandidates(sample []byte, enclosure byte) []string {
+	if d.candidates != nil {
+		return d.candidates
+	}
+	var candidates []string
+	seen := map[string]bool{}
+	for _, c := range sample {
+		if c == enclosure || c >= utf8.RuneSelf || d.nonDelimiterRegex.Match([]byte{c}) {
+			continue
+		}
+		delimiter := d.expandDelimiter(sample, rune(c), enclosure)
+		if !seen[delimiter] {
+			seen[delimiter] = true
+			candidates = append(candidates, delimiter)
+		}
+	}
+	return candidates
+}
+
+func scoreDelimiter(rows [][]byte, delimiter []byte, enclosure byte) DelimiterCandidate {
+	c := DelimiterCandidate{Delimiter: string(delimiter)}
+
+	counts := make([]int, len(rows))
+	frequencies := map[int]int{}
+	mode, max, sum := 0, 0, 0
+	for i, row := range rows {
+		counts[i] = countOutside(row, delimiter, enclosure)
+		frequencies[counts[i]]++
+		if n := frequencies[counts[i]]; n > max || n == max && counts[i] > mode {
+			mode, max = counts[i], n
+		}
+		sum += counts[i]
+	}
+	if frequencies[0] > 0 {
+		// Every row has at least two fields.
+		return c
+	}
+
+	c.Frequency = float64(sum) / float64(len(rows))
+	for _, count := range counts {
+		diff := float64(count) - c.Frequency
+		c.FieldCountVariance += diff * diff
+	}
+	c.FieldCountVariance /= float64(len(rows))
+	c.Consistency = float64(max) / float64(len(rows))
+	c.QuoteBalance = quoteBalance(rows, delimiter, enclosure)
+	c.Score = c.Consistency * c.QuoteBalance / (1 + c.FieldCountVariance)
+	return c
+}
+
+// splitRows splits sample into non-empty rows at "\r\n", "\n" and "\r", except
+// when inside fields enclosed by enclosure.
+func splitRows(sample []byte, enclosure byte) [][]byte {
+	var rows [][]byte
+	quoted, start := false, 0
+	for i := 0; i <= len(sample); i++ {
+		if i < len(sample) {
+			c := sample[i]
+			if c == enclosure && enclosure != 0 {
+				quoted = !quoted
+			}
+			if quoted || c != '\r' && c != '\n' {
+				continue
+			}
+		}
+		if i > start {
+			rows = append(rows, sample[start:i])
+		}
+		if i+1 < len(sample) && sample[i] == '\r' && sample[i+1] == '\n' {
+			i++
+		}
+		start = i + 1
+	}
+	return rows
+}
+
+// countOutside counts the occurrences of delimiter in row that are not inside
+// fields enclosed by enclosure.
+func countOutside(row, delimiter []byte, enclosure byte) int {
+	n, quoted := 0, false
+	for i := 0; i < len(row); i++ {
+		if row[i] == enclosure && enclosure != 0 {
+			quoted = !quoted
+			continue
+		}
+		if !quoted && bytes.HasPrefix(row[i:], delimiter) {
+			n++
+			i += len(delimiter) - 1
+		}
+	}
+	return n
+}
+
+// quoteBalance returns the fraction of opening and closing enclosures that are
+// directly next to delimiter or a row boundary.
+func quoteBalance(rows [][]byte, delimiter []byte, enclosure byte) float64 {
+	adjacent, total := 0, 0
+	for _, row := range rows {
+		quoted := false
+		for i := 0; i < len(row); i++ {
+			if row[i] != enclosure || enclosure == 0 {
+				continue
+			}
+			if quoted && i+1 < len(row) && row[i+1] == enclosure {
+				// Escaped by doubling.
+				i++
+				continue
+			}
+			var ok bool
+			if quoted {
+				ok = i+1 == len(row) || bytes.HasPrefix(row[i+1:], delimiter)
+			} else {
+				before := bytes.TrimRight(row[:i], " ")
+				ok = len(before) == 0 || bytes.HasSuffix(before, delimiter)
+			}
+			if ok {
+				adjacent++
+			}
+			total++
+			quoted = !quoted
+		}
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(adjacent) / float64(total)
+}
+
+// preference ranks delimiters scoring equally. Configured candidates are
+// preferred in the order given.
+func (d *detector) preference(delimiter string) int {
+	for i, candidate := range d.candidates {
+		if candidate == delimiter {
+			return i
+		}
+	}
+	if i := strings.Index(preferredDelimiters, delimiter); i != -1 && len(delimiter) == 1 {
+		return i
+	}
+	return len(preferredDelimiters)
+}
@@ -5,16 +5,26 @@ import (
 	"io"
 	"io/ioutil"
 	"regexp"
-
-	"github.com/jfyne/csvd"
+	"strings"
 )
 
 const (
+	// Number of bytes inspected when detecting row terminators.
+	rowTerminatorSampleSize = 128 * 1024
+
 	nonDelimiterRegexString = `[[:alnum:]\n\r]`
+
+	// Characters that may be combined into a multi-character delimiter.
+	// Excludes characters like '-' and '.' that commonly start or end values.
+	multiCharDelimiterParts = "|~^#:;,!*<>\t"
 )
 
 // New a detector.
 func New() Detector {
+	return newDetector()
+}
+
+func newDetector() *detector {
 	return &detector{
 		nonDelimiterRegex: regexp.MustCompile(nonDelimiterRegexString),
 	}
@@ -29,35 +39,73 @@ type Detector interface {
 // detector is the default implementation of Detector.
 type detector struct {
 	nonDelimiterRegex *regexp.Regexp
+	// Delimiters to consider. If nil, every character in the sample matching
+	// nonDelimiterRegex is considered.
+	candidates []string
 }
 
-// DetectRowTerminator finds the the row terminating string
+// DetectRowTerminator finds the the row terminating string. Returns "" on
+// errors and "\n" if there is no row terminator. See DetectRowTerminators for
+// details.
 func (d *detector) DetectRowTerminator(reader io.Reader) string {
-	KB := 1024
-	buf := make([]byte, 128*KB)
-	_, err := reader.Read(buf)
+	result, err := DetectRowTerminators(reader, '"')
 	if err != nil {
 		return ""
 	}
-
-	if bytes.Index(buf, []byte{'\r', '\n'}) != -1 {
-		return "\r\n"
-	}
-	if bytes.Index(buf, []byte{'\r'}) != -1 {
-		return "\r"
+	if result.Terminator == "" {
+		return "\n"
 	}
-
-	return "\n"
+	return result.Terminator
 }
 
-// DetectDelimiter finds a slice of delimiter string.
+// DetectDelimiter finds the potential delimiters, best first. See
+// RankDelimiters for how they are ranked.
 func (d *detector) DetectDelimiter(r io.Reader, enclosure byte) []string {
 	b, _ := ioutil.ReadAll(r)
-	if len(b) == 0 {
-		return []string{}
+	delimiters := []string{}
+	for _, c := range d.rankDelimiters(b, enclosure) {
+		delimiters = append(delimiters, c.Delimiter)
+	}
+	return delimiters
+}
+
+// expandDelimiter grows a single character delimiter into a multi-character
+// one, such as "||" or "~|~", if every occurrence of delimiter in the sample
+// is part of the same run of potential delimiter characters. Occurrences inside
+// fields enclosed by enclosure are ignored.
+func (d *detector) expandDelimiter(sample []byte, delimiter rune, enclosure byte) string {
+	if !strings.ContainsRune(multiCharDelimiterParts, delimiter) {
+		return string(delimiter)
+	}
+	isPart := func(c byte) bool {
+		return c != enclosure && strings.IndexByte(multiCharDelimiterParts, c) != -1
+	}
+
+	var run []byte
+	quoted := false
+	for i := 0; i < len(sample); i++ {
+		if sample[i] == enclosure && enclosure != 0 {
+			quoted = !quoted
+		}
+		if quoted || rune(sample[i]) != delimiter {
+			continue
+		}
+		start, end := i, i+1
+		for start > 0 && isPart(sample[start-1]) {
+			start--
+		}
+		for end < len(sample) && isPart(sample[end]) {
+			end++
+		}
+		if run == nil {
+			run = sample[start:end]
+		} else if !bytes.Equal(run, sample[start:end]) {
+			return string(delimiter)
+		}
+		i = end - 1
+	}
+	if run == nil {
+		return string(delimiter)
 	}
-	r = bytes.NewReader(b)
-	csvReader := csvd.NewReader(r)
-	delimiter := csvReader.Comma
-	return []string{string(delimiter)}
+	return string(run)
 }
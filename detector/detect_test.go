@@ -1,8 +1,11 @@
 package detector
 
 import (
+	"bytes"
+	stdcsv "encoding/csv"
 	"errors"
 	"io"
+	"io/ioutil"
 	"os"
 	"regexp"
 	"strings"
@@ -10,9 +13,57 @@ import (
 
 	"fmt"
 
+	csv "github.com/eltorocorp/go-csv"
+
 	"github.com/stretchr/testify/assert"
 )
 
+// TestDetectDelimiterCorpus checks the detected delimiter of each file in
+// Fixtures/corpus, many of which contain the delimiters of other dialects in
+// quoted fields. Records read using the sniffed dialect are compared against
+// encoding/csv where it supports the dialect.
+func TestDetectDelimiterCorpus(t *testing.T) {
+	detector := New()
+
+	corpus := map[string]string{
+		"colon.csv":            ":",
+		"doubled-quotes.csv":   ",",
+		"multichar.csv":        "||",
+		"quoted-newline.csv":   ",",
+		"quoted-pipe.csv":      "|",
+		"quoted-semicolon.csv": ";",
+		"quoted-tab.tsv":       "\t",
+	}
+	for name, expected := range corpus {
+		b, err := ioutil.ReadFile("./Fixtures/corpus/" + name)
+		assert.NoError(t, err)
+
+		delimiters := detector.DetectDelimiter(bytes.NewReader(b), '"')
+		if assert.NotEmpty(t, delimiters, name) {
+			assert.Equal(t, expected, delimiters[0], name)
+		}
+
+		dialect, _, err := Sniff(bytes.NewReader(b), 0)
+		assert.NoError(t, err, name)
+		delimiter := dialect.DelimiterString
+		if delimiter == "" {
+			delimiter = string(dialect.Delimiter)
+		}
+		assert.Equal(t, expected, delimiter, name)
+
+		records, err := csv.NewDialectReader(bytes.NewReader(b), dialect).ReadAll()
+		assert.NoError(t, err, name)
+		if len(expected) > 1 {
+			continue
+		}
+		std := stdcsv.NewReader(bytes.NewReader(b))
+		std.Comma = rune(expected[0])
+		stdRecords, err := std.ReadAll()
+		assert.NoError(t, err, name)
+		assert.Equal(t, stdRecords, records, name)
+	}
+}
+
 func TestIsPotentialDelimiter(t *testing.T) {
 	tests := []struct {
 		input    byte
@@ -119,3 +170,279 @@ type badRead struct {
 func (b badRead) Read(p []byte) (int, error) {
 	return 0, errors.New("woowoowoo")
 }
+
+func TestDetectMultiCharDelimiter(t *testing.T) {
+	detector := New()
+
+	testCases := []struct {
+		input     string
+		delimiter string
+	}{
+		{"a||b||c\nd||e||f\n", "||"},
+		{"a~|~b~|~c\nd~|~e~|~f\n", "~|~"},
+		{"a,-1\nb,2\n", ","},
+	}
+
+	for _, tc := range testCases {
+		delimiters := detector.DetectDelimiter(strings.NewReader(tc.input), '"')
+		assert.Equal(t, []string{tc.delimiter}, delimiters)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	file1, err := os.Open("./Fixtures/test1.csv")
+	assert.NoError(t, err)
+	defer file1.Close()
+
+	dialect, confidence, err := Sniff(file1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, ',', dialect.Delimiter)
+	assert.Equal(t, '"', dialect.QuoteChar)
+	assert.Equal(t, csv.DoDoubleQuote, dialect.DoubleQuote)
+	assert.Equal(t, "\n", dialect.LineTerminator)
+	assert.True(t, confidence > 0.5, "confidence %v", confidence)
+
+	testCases := []struct {
+		input    string
+		expected csv.Dialect
+	}{
+		{
+			"a;'b;c';d\r\ne;f;'g\r\nh'\r\n",
+			csv.Dialect{Delimiter: ';', QuoteChar: '\'', DoubleQuote: csv.DoDoubleQuote, LineTerminator: "\r\n"},
+		},
+		{
+			"a\t\"b\\\"c\"\td\ne\t\"f\"\tg\n",
+			csv.Dialect{Delimiter: '\t', QuoteChar: '"', DoubleQuote: csv.NoDoubleQuote, EscapeChar: '\\', LineTerminator: "\n"},
+		},
+		{
+			"a, b, c\rd, e, f\r",
+			csv.Dialect{Delimiter: ',', QuoteChar: '"', DoubleQuote: csv.DoDoubleQuote, LineTerminator: "\r", SkipInitialSpace: true},
+		},
+		{
+			"a||b||c\nd||e||f\n",
+			csv.Dialect{DelimiterString: "||", QuoteChar: '"', DoubleQuote: csv.DoDoubleQuote, LineTerminator: "\n"},
+		},
+	}
+	for _, tc := range testCases {
+		dialect, _, err := Sniff(strings.NewReader(tc.input), 0)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, dialect, "input %q", tc.input)
+	}
+
+	_, _, err = Sniff(strings.NewReader(""), 0)
+	assert.Equal(t, ErrEmptySample, err)
+	_, _, err = Sniff(strings.NewReader("abc\ndef\n"), 0)
+	assert.Equal(t, ErrNoDialect, err)
+}
+
+func TestHasHeader(t *testing.T) {
+	dialect := csv.Dialect{Delimiter: ','}
+
+	for _, fixture := range []string{"./Fixtures/test1.csv", "./Fixtures/test2.csv"} {
+		file, err := os.Open(fixture)
+		assert.NoError(t, err)
+		defer file.Close()
+
+		hasHeader, confidence := HasHeader(file, dialect)
+		assert.True(t, hasHeader, fixture)
+		assert.True(t, confidence > 0, fixture)
+	}
+
+	hasHeader, _ := HasHeader(strings.NewReader("1,ab\n2,cd\n3,ef\n"), dialect)
+	assert.False(t, hasHeader)
+}
+
+func TestDetectHeader(t *testing.T) {
+	dialect := csv.Dialect{Delimiter: ','}
+
+	testCases := []struct {
+		input    string
+		preamble int
+		rows     int
+	}{
+		{"Name,Age\nBob,42\nAlice,37\n", 0, 1},
+		{"Report for 2020\n\nName,Age\n,years\nBob,42\nAlice,37\n", 1, 2},
+		{"Bob,42\nAlice,37\n", 0, 0},
+	}
+	for _, tc := range testCases {
+		h := DetectHeader(strings.NewReader(tc.input), dialect)
+		assert.Equal(t, tc.preamble, h.Preamble, tc.input)
+		assert.Equal(t, tc.rows, h.Rows, tc.input)
+	}
+}
+
+func TestRankDelimiters(t *testing.T) {
+	detector := NewWithCandidates()
+
+	candidates := detector.RankDelimiters(strings.NewReader("a;\"b,c\";d\ne;\"f,g,h\";i\n"), '"')
+	assert.Equal(t, []DelimiterCandidate{
+		{Delimiter: ";", Score: 1, Consistency: 1, QuoteBalance: 1, Frequency: 2},
+	}, candidates)
+
+	candidates = detector.RankDelimiters(strings.NewReader("a;b,c\nd;e,f\ng;h,i,j\n"), '"')
+	assert.Equal(t, 2, len(candidates))
+	assert.Equal(t, ";", candidates[0].Delimiter)
+	assert.Equal(t, ",", candidates[1].Delimiter)
+	assert.True(t, candidates[1].FieldCountVariance > 0)
+
+	detector = NewWithCandidates("||", ";")
+	candidates = detector.RankDelimiters(strings.NewReader("a||b;c\nd||e;f\n"), '"')
+	assert.Equal(t, 2, len(candidates))
+	assert.Equal(t, "||", candidates[0].Delimiter)
+	assert.Equal(t, ";", candidates[1].Delimiter)
+
+	delimiters := detector.DetectDelimiter(strings.NewReader("a||b;c\nd||e;f\n"), '"')
+	assert.Equal(t, []string{"||", ";"}, delimiters)
+}
+
+func TestPeek(t *testing.T) {
+	input := "a;b\nc;d\ne;f\n"
+	r := strings.NewReader(input)
+
+	dialect, replay, err := Peek(r, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, ';', dialect.Delimiter)
+	assert.Equal(t, 4, r.Len(), "only the prefix should be read")
+
+	b, err := ioutil.ReadAll(replay)
+	assert.NoError(t, err)
+	assert.Equal(t, input, string(b))
+
+	_, replay, err = Peek(strings.NewReader("abc"), 0)
+	assert.Equal(t, ErrNoDialect, err)
+	b, _ = ioutil.ReadAll(replay)
+	assert.Equal(t, "abc", string(b))
+}
+
+func TestNewSniffingReader(t *testing.T) {
+	r, err := csv.NewSniffingReader(strings.NewReader("a|\"b|c\"\r\nd|e\r\n"))
+	assert.NoError(t, err)
+
+	records, err := r.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b|c"}, {"d", "e"}}, records)
+}
+
+func TestDetectRowTerminators(t *testing.T) {
+	result, err := DetectRowTerminators(strings.NewReader("a,\"b\r\nc\"\nd\n\"e\r\"\r\nf\n"), '"')
+	assert.NoError(t, err)
+	assert.Equal(t, RowTerminators{
+		Terminator: "\n",
+		Counts:     map[string]int{"\r\n": 1, "\n": 3, "\r": 0},
+		Mixed:      true,
+	}, result)
+
+	_, err = DetectRowTerminators(strings.NewReader(""), '"')
+	assert.Equal(t, ErrEmptySample, err)
+	_, err = DetectRowTerminators(badRead{}, '"')
+	assert.Error(t, err)
+
+	// A large sample needing several reads to fill.
+	r := io.MultiReader(strings.NewReader("a\r\n"), strings.NewReader(strings.Repeat("b\r\n", 1000)))
+	result, err = DetectRowTerminators(r, '"')
+	assert.NoError(t, err)
+	assert.Equal(t, "\r\n", result.Terminator)
+	assert.Equal(t, 1001, result.Counts["\r\n"])
+	assert.False(t, result.Mixed)
+}
+
+func TestInferSchema(t *testing.T) {
+	input := "id,price,active,created,uuid,email,note,blank\n" +
+		"1,9.99,true,13/01/2020,6ba7b810-9dad-11d1-80b4-00c04fd430c8,a@example.com,hello,\n" +
+		"2,10,no,02/02/2020,6ba7b811-9dad-11d1-80b4-00c04fd430c8,b@example.org,,NULL\n" +
+		"3,NA,yes,,6ba7b812-9dad-11d1-80b4-00c04fd430c8,c@example.net,wörld,\n"
+	r := csv.NewDialectReader(strings.NewReader(input), csv.Dialect{Delimiter: ','})
+
+	profiles, err := InferSchemaWithOptions(r, 10, SchemaOptions{Header: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []ColumnProfile{
+		{Index: 0, Name: "id", Type: TypeInteger, MaxLength: 1},
+		{Index: 1, Name: "price", Type: TypeFloat, NullRatio: 1.0 / 3, MaxLength: 4},
+		{Index: 2, Name: "active", Type: TypeBoolean, MaxLength: 4},
+		{Index: 3, Name: "created", Type: TypeDateTime, Layout: "02/01/2006", NullRatio: 1.0 / 3, MaxLength: 10},
+		{Index: 4, Name: "uuid", Type: TypeUUID, MaxLength: 36},
+		{Index: 5, Name: "email", Type: TypeEmail, MaxLength: 13},
+		{Index: 6, Name: "note", Type: TypeString, NullRatio: 1.0 / 3, MaxLength: 5},
+		{Index: 7, Name: "blank", Type: TypeEmpty, NullRatio: 1},
+	}, profiles)
+}
+
+func TestInferSchemaLocale(t *testing.T) {
+	r := csv.NewDialectReader(strings.NewReader("1.000,5;3\n2,25;4\n"), csv.Dialect{Delimiter: ';'})
+
+	profiles, err := InferSchemaWithOptions(r, 10, SchemaOptions{
+		Locale: Locale{DecimalSeparator: ',', ThousandsSeparator: '.'},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, TypeFloat, profiles[0].Type)
+	assert.Equal(t, TypeInteger, profiles[1].Type)
+}
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		input    string
+		encoding string
+		certain  bool
+	}{
+		{"\xef\xbb\xbfa,b\n", csv.EncodingUTF8, true},
+		{"\xff\xfea\x00,\x00b\x00", csv.EncodingUTF16LE, true},
+		{"\xfe\xff\x00a\x00,\x00b", csv.EncodingUTF16BE, true},
+		{"a,b\nc,\xc3\xa9\n", csv.EncodingUTF8, true},
+		{"a\x00,\x00b\x00\n\x00c\x00,\x00d\x00\n\x00", csv.EncodingUTF16LE, false},
+		{"\x00a\x00,\x00b\x00\n\x00c\x00,\x00d\x00\n", csv.EncodingUTF16BE, false},
+		{"name,price\ncaf\xe9,5\x80\n\x93quoted\x94,1\n", csv.EncodingWindows1252, false},
+		{"name,code\ncaf\xe9,\x81\n", csv.EncodingLatin1, false},
+	}
+	for _, test := range tests {
+		name, confidence := DetectEncoding(strings.NewReader(test.input))
+		assert.Equal(t, test.encoding, name, test.input)
+		if test.certain {
+			assert.Equal(t, 1.0, confidence, test.input)
+		} else {
+			assert.True(t, confidence > 0.5 && confidence <= 1, test.input)
+		}
+
+		// The detected encoding reads the input back.
+		r := csv.NewDialectReader(strings.NewReader(test.input), csv.Dialect{Delimiter: ',', Encoding: name})
+		_, err := r.ReadAll()
+		assert.NoError(t, err)
+	}
+
+	name, confidence := DetectEncoding(strings.NewReader(""))
+	assert.Equal(t, csv.EncodingUTF8, name)
+	assert.Equal(t, 0.0, confidence)
+}
+
+func TestDetectFixedWidth(t *testing.T) {
+	input := "ID     NAME           AMOUNT\r\n" +
+		"000001 ADAM WEST       12.50\r\n" +
+		"000002 BOBBY HILL     100.00\r\n" +
+		"\r\n" +
+		"000003 CARL             1.99\r\n"
+	spans, err := DetectFixedWidth(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []csv.ColumnSpan{{Start: 0, End: 7}, {Start: 7, End: 22}, {Start: 22}}, spans)
+
+	records, err := csv.NewFixedWidthReader(strings.NewReader(input), spans).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"ID", "NAME", "AMOUNT"},
+		{"000001", "ADAM WEST", "12.50"},
+		{"000002", "BOBBY HILL", "100.00"},
+		{"000003", "CARL", "1.99"},
+	}, records)
+
+	// Columns are counted in terminal cells, like csv.FixedWidthWriter does.
+	input = "日本 x\nab   y\n"
+	spans, err = DetectFixedWidth(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Equal(t, []csv.ColumnSpan{{Start: 0, End: 5}, {Start: 5}}, spans)
+	records, err = csv.NewFixedWidthReader(strings.NewReader(input), spans).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"日本", "x"}, {"ab", "y"}}, records)
+
+	_, err = DetectFixedWidth(strings.NewReader("a,b\nc,d\n"))
+	assert.Equal(t, ErrNotFixedWidth, err)
+	_, err = DetectFixedWidth(strings.NewReader("a   b\n"))
+	assert.Equal(t, ErrNotFixedWidth, err)
+}
@@ -0,0 +1,119 @@
+package detector
+
+import (
+	"bytes"
+	"io"
+	"unicode/utf8"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// Byte order marks, longest first.
+var byteOrderMarks = []struct {
+	bom      string
+	encoding string
+}{
+	{"\xef\xbb\xbf", csv.EncodingUTF8},
+	{"\xff\xfe", csv.EncodingUTF16LE},
+	{"\xfe\xff", csv.EncodingUTF16BE},
+}
+
+// DetectEncoding guesses the character encoding of the data read from r. The
+// returned name can be used as csv.Dialect.Encoding and confidence is between
+// 0 and 1. Byte order marks are recognised with certainty. Otherwise UTF-16 is
+// recognised by its many zero bytes, valid UTF-8 is reported as such and
+// anything else is assumed to be Windows-1252 or, if it uses bytes undefined
+// there, ISO-8859-1. Empty data is reported as UTF-8 with zero confidence.
+func DetectEncoding(r io.Reader) (name string, confidence float64) {
+	// Read errors leave what was read before them to detect from.
+	sample, more, _ := readPrefix(r, DefaultSampleSize)
+	if len(sample) == 0 {
+		return csv.EncodingUTF8, 0
+	}
+
+	for _, m := range byteOrderMarks {
+		if bytes.HasPrefix(sample, []byte(m.bom)) {
+			return m.encoding, 1
+		}
+	}
+
+	if name, confidence := detectUTF16(sample); name != "" {
+		return name, confidence
+	}
+
+	if more {
+		// Don't let a character cut in half by the end of the sample count
+		// against UTF-8.
+		for i := 1; i < utf8.UTFMax && i <= len(sample); i++ {
+			if utf8.RuneStart(sample[len(sample)-i]) {
+				if !utf8.FullRune(sample[len(sample)-i:]) {
+					sample = sample[:len(sample)-i]
+				}
+				break
+			}
+		}
+	}
+	if utf8.Valid(sample) {
+		return csv.EncodingUTF8, 1
+	}
+	return detectSingleByte(sample)
+}
+
+// detectUTF16 recognises UTF-16 text without a byte order mark by zero bytes,
+// which are the high bytes of ASCII characters, being common at either even or
+// odd offsets but not both. Returns "" if sample doesn't look like UTF-16.
+func detectUTF16(sample []byte) (string, float64) {
+	var zeros [2]int
+	for i, c := range sample {
+		if c == 0 {
+			zeros[i%2]++
+		}
+	}
+	units := len(sample) / 2
+	if units == 0 {
+		return "", 0
+	}
+	even, odd := float64(zeros[0])/float64(units), float64(zeros[1])/float64(units)
+	switch {
+	case odd > 0.3 && even < 0.05:
+		return csv.EncodingUTF16LE, odd - even
+	case even > 0.3 && odd < 0.05:
+		return csv.EncodingUTF16BE, even - odd
+	}
+	return "", 0
+}
+
+// detectSingleByte tells Windows-1252 from ISO-8859-1 by its printable
+// characters in the range 0x80 to 0x9F, where ISO-8859-1 has rarely used
+// control characters. Confidence is the fraction of non-ASCII bytes that are
+// letters or typographic punctuation.
+func detectSingleByte(sample []byte) (string, float64) {
+	name := csv.EncodingWindows1252
+	high, plausible := 0, 0
+	for _, c := range sample {
+		if c < 0x80 {
+			continue
+		}
+		high++
+		switch {
+		case c == 0x81 || c == 0x8D || c == 0x8F || c == 0x90 || c == 0x9D:
+			// Undefined in Windows-1252.
+			name = csv.EncodingLatin1
+		case c < 0xA0 || c >= 0xC0 && c != 0xD7 && c != 0xF7:
+			plausible++
+		}
+	}
+	if name == csv.EncodingLatin1 {
+		// Any plausibility came from Windows-1252 punctuation.
+		plausible = 0
+		for _, c := range sample {
+			if c >= 0xC0 && c != 0xD7 && c != 0xF7 {
+				plausible++
+			}
+		}
+	}
+	if high == 0 {
+		return name, 0
+	}
+	return name, 0.5 + 0.5*float64(plausible)/float64(high)
+}
@@ -0,0 +1,85 @@
+package detector
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// Minimum number of non-blank lines needed to detect a fixed-width layout.
+const minFixedWidthLines = 2
+
+// ErrNotFixedWidth is returned when the sample has no column positions that
+// are blank on every line.
+var ErrNotFixedWidth = errors.New("detector: not a fixed-width layout")
+
+// DetectFixedWidth detects the columns of a fixed-width file by finding the
+// terminal cells that are blank on every sampled line. Each column starts where
+// text does after such a gap and extends up to the next column, so that both
+// left and right aligned values fit. The last column extends to the end of
+// the line. The result can be passed to csv.NewFixedWidthReader.
+func DetectFixedWidth(r io.Reader) ([]csv.ColumnSpan, error) {
+	prefix, more, err := readPrefix(r, DefaultSampleSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines [][]rune
+	for _, line := range splitRows(sampleOf(prefix, more), 0) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, cells(string(line)))
+		}
+	}
+	if len(lines) < minFixedWidthLines {
+		return nil, ErrNotFixedWidth
+	}
+
+	width := 0
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+	blank := make([]bool, width)
+	for i := range blank {
+		blank[i] = true
+		for _, line := range lines {
+			if i < len(line) && line[i] != ' ' {
+				blank[i] = false
+				break
+			}
+		}
+	}
+
+	var spans []csv.ColumnSpan
+	for i := range blank {
+		if blank[i] || i > 0 && !blank[i-1] {
+			continue
+		}
+		if len(spans) > 0 {
+			spans[len(spans)-1].End = i
+		}
+		spans = append(spans, csv.ColumnSpan{Start: i})
+	}
+	if len(spans) < 2 {
+		return nil, ErrNotFixedWidth
+	}
+	// Leading blanks belong to the first column.
+	spans[0].Start = 0
+	return spans, nil
+}
+
+// cells returns the character in each terminal cell of line. A wide character
+// fills all of its cells and characters of zero width are left out. See
+// csv.RuneWidth.
+func cells(line string) []rune {
+	var cells []rune
+	for _, r := range line {
+		for w := csv.RuneWidth(r); w > 0; w-- {
+			cells = append(cells, r)
+		}
+	}
+	return cells
+}
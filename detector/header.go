@@ -0,0 +1,157 @@
+package detector
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+const (
+	// Number of records inspected when detecting headers.
+	headerSampleRows = 50
+	// Maximum number of rows a single header can span.
+	maxHeaderRows = 3
+)
+
+// Header describes the rows preceding the data in a CSV file.
+type Header struct {
+	// Number of rows before the header, such as titles or notes. These have a
+	// different number of fields than the data.
+	Preamble int
+	// Number of header rows. Zero if there is no header.
+	Rows int
+	// How certain the detection is, from 0 to 1.
+	Confidence float64
+}
+
+// HasHeader tells whether the first row read from r, using dialect d, is a
+// header. The returned confidence is between 0 and 1.
+func HasHeader(r io.Reader, dialect csv.Dialect) (bool, float64) {
+	h := DetectHeader(r, dialect)
+	return h.Rows > 0 && h.Preamble == 0, h.Confidence
+}
+
+// DetectHeader detects preamble rows and header rows at the start of the
+// data read from r using dialect d. Much like Python's Sniffer.has_header, a
+// row is considered a header if its values do not fit the type or length
+// profile of the columns in the rows following it.
+func DetectHeader(r io.Reader, dialect csv.Dialect) Header {
+	reader := csv.NewDialectReader(r, dialect)
+	reader.OnError = func([]byte, *csv.ParseError) {}
+	reader.MaxRecords = headerSampleRows
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			break
+		}
+		if len(row) == 1 && row[0] == "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	h := Header{Preamble: countPreamble(rows)}
+	rows = rows[h.Preamble:]
+
+	// Try the longest header first since the rows of a multi-row header only
+	// stand out from the data following all of them.
+	for n := maxHeaderRows; n > 0; n-- {
+		if n >= len(rows) {
+			continue
+		}
+		votes, columns := headerVotes(rows[0], rows[n:])
+		if votes <= 0 {
+			if n == 1 && columns > 0 {
+				h.Confidence = float64(-votes) / float64(columns)
+			}
+			continue
+		}
+		isHeader := true
+		for _, row := range rows[1:n] {
+			if v, _ := headerVotes(row, rows[n:]); v <= 0 {
+				isHeader = false
+				break
+			}
+		}
+		if isHeader {
+			h.Rows = n
+			h.Confidence = float64(votes) / float64(columns)
+			break
+		}
+	}
+	return h
+}
+
+// countPreamble counts the leading rows that have a different number of
+// fields than the most common one.
+func countPreamble(rows [][]string) int {
+	counts := map[int]int{}
+	fields, max := 0, 0
+	for _, row := range rows {
+		counts[len(row)]++
+		if n := counts[len(row)]; n > max {
+			fields, max = len(row), n
+		}
+	}
+	n := 0
+	for n < len(rows) && len(rows[n]) != fields {
+		n++
+	}
+	return n
+}
+
+// headerVotes compares each value in header to the values of the same column
+// in data. A vote is cast for each column having a consistent type or length:
+// positive if the header value differs from it, negative otherwise.
+func headerVotes(header []string, data [][]string) (votes, columns int) {
+	for i, value := range header {
+		numeric, length, ok := profileColumn(data, i)
+		if !ok {
+			continue
+		}
+		columns++
+		if numeric && !isNumber(value) || !numeric && len(value) != length {
+			votes++
+		} else {
+			votes--
+		}
+	}
+	return votes, columns
+}
+
+// profileColumn tells whether all non-empty values of column i in rows are
+// numbers or, if not, whether they all have the same length.
+func profileColumn(rows [][]string, i int) (numeric bool, length int, ok bool) {
+	var values []string
+	for _, row := range rows {
+		if i < len(row) && row[i] != "" {
+			values = append(values, row[i])
+		}
+	}
+	if len(values) == 0 {
+		return false, 0, false
+	}
+
+	numeric = true
+	for _, value := range values {
+		numeric = numeric && isNumber(value)
+	}
+	if numeric {
+		return true, 0, true
+	}
+	length = len(values[0])
+	for _, value := range values {
+		if len(value) != length {
+			return false, 0, false
+		}
+	}
+	return false, length, true
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return err == nil
+}
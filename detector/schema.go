@@ -0,0 +1,285 @@
+package detector
+
+import (
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/eltorocorp/go-csv/interfaces"
+)
+
+// ColumnType is the kind of values a column holds.
+type ColumnType string
+
+// Column types recognised by DefaultRecognizers. TypeEmpty and TypeString are
+// used for columns without values and columns not matching any Recognizer.
+const (
+	TypeEmpty    ColumnType = "empty"
+	TypeInteger  ColumnType = "integer"
+	TypeFloat    ColumnType = "float"
+	TypeBoolean  ColumnType = "boolean"
+	TypeDateTime ColumnType = "datetime"
+	TypeUUID     ColumnType = "uuid"
+	TypeEmail    ColumnType = "email"
+	TypeString   ColumnType = "string"
+)
+
+// ColumnProfile describes the values sampled from a column.
+type ColumnProfile struct {
+	Index int
+	// Name of the column, if the sample started with a header.
+	Name string
+	Type ColumnType
+	// The variant of Type all values had, such as a time layout for
+	// TypeDateTime.
+	Layout string
+	// Fraction of sampled rows where the value is empty or null.
+	NullRatio float64
+	// Length of the longest value, in runes.
+	MaxLength int
+}
+
+// Locale holds hints on how numbers are formatted.
+type Locale struct {
+	DecimalSeparator rune // Defaults to '.'.
+	// Separator between groups of digits, such as ',' in "1,000". Zero if
+	// numbers are not grouped.
+	ThousandsSeparator rune
+}
+
+// A Recognizer recognises the values of a type. Variants lists alternative
+// formats, such as time layouts, of which all values in a column must use the
+// same one.
+type Recognizer struct {
+	Type     ColumnType
+	Variants []string
+	// Match tells whether value is of Type. variant is one of Variants, or ""
+	// if there are none.
+	Match func(value, variant string, locale Locale) bool
+}
+
+// SchemaOptions configures InferSchemaWithOptions.
+type SchemaOptions struct {
+	// Recognizers to try, in order of preference. Defaults to
+	// DefaultRecognizers.
+	Recognizers []Recognizer
+	Locale      Locale
+	// Whether the first record holds column names.
+	Header bool
+	// Values considered null, in addition to the empty string. Defaults to
+	// DefaultNullValues.
+	NullValues []string
+}
+
+// DefaultNullValues are the values considered null by default.
+var DefaultNullValues = []string{"NULL", "null", "NA", "N/A", "-"}
+
+var (
+	uuidRegex  = regexp.MustCompile(`^[[:xdigit:]]{8}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{4}-[[:xdigit:]]{12}$`)
+	emailRegex = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// DefaultRecognizers are used unless SchemaOptions.Recognizers is set.
+var DefaultRecognizers = []Recognizer{
+	{
+		Type: TypeInteger,
+		Match: func(value, _ string, locale Locale) bool {
+			_, err := strconv.ParseInt(normalizeNumber(value, locale), 10, 64)
+			return err == nil
+		},
+	},
+	{
+		Type: TypeFloat,
+		Match: func(value, _ string, locale Locale) bool {
+			_, err := strconv.ParseFloat(normalizeNumber(value, locale), 64)
+			return err == nil
+		},
+	},
+	{
+		Type: TypeBoolean,
+		Match: func(value, _ string, _ Locale) bool {
+			switch strings.ToLower(value) {
+			case "true", "false", "t", "f", "yes", "no", "y", "n":
+				return true
+			}
+			return false
+		},
+	},
+	{
+		Type: TypeDateTime,
+		Variants: []string{
+			time.RFC3339Nano,
+			"2006-01-02T15:04:05",
+			"2006-01-02 15:04:05",
+			"2006-01-02",
+			"2006/01/02",
+			"01/02/2006",
+			"02/01/2006",
+			"02.01.2006",
+			"Jan 2, 2006",
+			"2 Jan 2006",
+			"15:04:05",
+			"15:04",
+		},
+		Match: func(value, layout string, _ Locale) bool {
+			_, err := time.Parse(layout, value)
+			return err == nil
+		},
+	},
+	{
+		Type: TypeUUID,
+		Match: func(value, _ string, _ Locale) bool {
+			return uuidRegex.MatchString(value)
+		},
+	},
+	{
+		Type: TypeEmail,
+		Match: func(value, _ string, _ Locale) bool {
+			return emailRegex.MatchString(value)
+		},
+	},
+}
+
+// normalizeNumber rewrites a number formatted according to locale the way
+// strconv expects it.
+func normalizeNumber(value string, locale Locale) string {
+	value = strings.TrimSpace(value)
+	if locale.ThousandsSeparator != 0 {
+		value = strings.Replace(value, string(locale.ThousandsSeparator), "", -1)
+	}
+	if locale.DecimalSeparator != 0 && locale.DecimalSeparator != '.' {
+		if strings.ContainsRune(value, '.') {
+			// Not a number in this locale.
+			return ""
+		}
+		value = strings.Replace(value, string(locale.DecimalSeparator), ".", -1)
+	}
+	return value
+}
+
+// InferSchema guesses the type of each column from the first sampleRows
+// records read from r, using the default SchemaOptions.
+func InferSchema(r interfaces.Reader, sampleRows int) ([]ColumnProfile, error) {
+	return InferSchemaWithOptions(r, sampleRows, SchemaOptions{})
+}
+
+// InferSchemaWithOptions guesses the type of each column from the first
+// sampleRows records read from r. A column gets the type of the first
+// Recognizer matching all its non-null values, and TypeString if there is
+// none.
+func InferSchemaWithOptions(r interfaces.Reader, sampleRows int, opts SchemaOptions) ([]ColumnProfile, error) {
+	if opts.Recognizers == nil {
+		opts.Recognizers = DefaultRecognizers
+	}
+	if opts.NullValues == nil {
+		opts.NullValues = DefaultNullValues
+	}
+
+	var names []string
+	if opts.Header {
+		var err error
+		if names, err = r.Read(); err != nil && err != io.EOF {
+			return nil, err
+		}
+	}
+
+	var columns []*columnState
+	rows := 0
+	for ; rows < sampleRows; rows++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for len(columns) < len(record) {
+			columns = append(columns, newColumnState(opts.Recognizers))
+		}
+		for i, value := range record {
+			if !isNull(value, opts.NullValues) {
+				columns[i].add(value, opts)
+			}
+		}
+	}
+
+	profiles := make([]ColumnProfile, len(columns))
+	for i, c := range columns {
+		profiles[i] = c.profile(opts.Recognizers, rows)
+		profiles[i].Index = i
+		if i < len(names) {
+			profiles[i].Name = names[i]
+		}
+	}
+	return profiles, nil
+}
+
+func isNull(value string, nullValues []string) bool {
+	if value == "" {
+		return true
+	}
+	for _, null := range nullValues {
+		if value == null {
+			return true
+		}
+	}
+	return false
+}
+
+// columnState keeps track of which Recognizers, and which of their variants,
+// match all values of a column seen so far.
+type columnState struct {
+	// Variants still matching, per recognizer. nil once a recognizer stopped
+	// matching.
+	variants  [][]string
+	values    int // Number of non-null values.
+	maxLength int
+}
+
+func newColumnState(recognizers []Recognizer) *columnState {
+	c := &columnState{variants: make([][]string, len(recognizers))}
+	for i, recognizer := range recognizers {
+		c.variants[i] = recognizer.Variants
+		if len(c.variants[i]) == 0 {
+			c.variants[i] = []string{""}
+		}
+	}
+	return c
+}
+
+func (c *columnState) add(value string, opts SchemaOptions) {
+	c.values++
+	if n := utf8.RuneCountInString(value); n > c.maxLength {
+		c.maxLength = n
+	}
+	for i, recognizer := range opts.Recognizers {
+		var matching []string
+		for _, variant := range c.variants[i] {
+			if recognizer.Match(value, variant, opts.Locale) {
+				matching = append(matching, variant)
+			}
+		}
+		c.variants[i] = matching
+	}
+}
+
+func (c *columnState) profile(recognizers []Recognizer, rows int) ColumnProfile {
+	p := ColumnProfile{Type: TypeString, MaxLength: c.maxLength}
+	if rows > 0 {
+		p.NullRatio = float64(rows-c.values) / float64(rows)
+	}
+	if c.values == 0 {
+		p.Type = TypeEmpty
+		return p
+	}
+	for i, recognizer := range recognizers {
+		if len(c.variants[i]) > 0 {
+			p.Type, p.Layout = recognizer.Type, c.variants[i][0]
+			break
+		}
+	}
+	return p
+}
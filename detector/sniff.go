@@ -0,0 +1,250 @@
+package detector
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// DefaultSampleSize is the number of bytes inspected when a non-positive
+// sample size is given.
+const DefaultSampleSize = 64 * 1024
+
+const (
+	// Delimiters and quote characters Sniff considers, in order of preference.
+	sniffDelimiters = ",\t;|:^~ "
+	sniffQuotes     = `"'`
+)
+
+var (
+	// ErrEmptySample is returned when there is no data to detect anything from.
+	ErrEmptySample = errors.New("detector: empty sample")
+	// ErrNoDialect is returned when no delimiter splits the sample into a
+	// consistent number of fields.
+	ErrNoDialect = errors.New("detector: could not determine dialect")
+)
+
+// Confidence tells how certain a detection is, from 0 (a guess) to 1
+// (certain).
+type Confidence float64
+
+// Sniff infers the dialect of at most sampleSize bytes read from r, much like
+// Python's csv.Sniffer: the delimiter, quote character, whether quotes are
+// escaped by doubling them or by an escape character, line terminator and
+// whether spaces following delimiters should be skipped.
+func Sniff(r io.Reader, sampleSize int) (csv.Dialect, Confidence, error) {
+	prefix, more, err := readPrefix(r, sampleSize)
+	if err != nil {
+		return csv.Dialect{}, 0, err
+	}
+	return newDetector().sniff(sampleOf(prefix, more))
+}
+
+// Peek is like Sniff, but also returns a reader that replays the inspected
+// prefix of r before continuing with the rest of it. Only up to n bytes are
+// read from r. The returned reader is usable even if detection fails.
+func Peek(r io.Reader, n int) (csv.Dialect, io.Reader, error) {
+	prefix, more, err := readPrefix(r, n)
+	replay := io.MultiReader(bytes.NewReader(prefix), r)
+	if err != nil {
+		return csv.Dialect{}, replay, err
+	}
+	dialect, _, err := newDetector().sniff(sampleOf(prefix, more))
+	return dialect, replay, err
+}
+
+func init() {
+	csv.RegisterSniffer(func(r io.Reader) (csv.Dialect, io.Reader, error) {
+		return Peek(r, DefaultSampleSize)
+	})
+}
+
+// readPrefix reads up to size bytes from r. more tells whether there might be
+// more to read.
+func readPrefix(r io.Reader, size int) (prefix []byte, more bool, err error) {
+	if size <= 0 {
+		size = DefaultSampleSize
+	}
+	prefix = make([]byte, size)
+	n, err := io.ReadFull(r, prefix)
+	switch err {
+	case nil:
+		return prefix, true, nil
+	case io.ErrUnexpectedEOF:
+		return prefix[:n], false, nil
+	case io.EOF:
+		return nil, false, ErrEmptySample
+	}
+	return prefix[:n], false, err
+}
+
+// sampleOf cuts prefix at its last line terminator if there is more to read,
+// to not end the sample in a partial record.
+func sampleOf(prefix []byte, more bool) []byte {
+	if !more {
+		return prefix
+	}
+	if i := bytes.LastIndexAny(prefix, "\r\n"); i > 0 {
+		return prefix[:i+1]
+	}
+	return prefix
+}
+
+// A trial is the outcome of parsing a sample using a candidate dialect.
+type trial struct {
+	dialect csv.Dialect
+	rows    [][]string
+	// Fraction of records having the most common number of fields. Zero if
+	// that number is less than two.
+	consistency float64
+	// Number of fields in the sample starting with the quote character.
+	quoted int
+}
+
+// betterThan tells whether t is more likely to be the right dialect than u.
+func (t trial) betterThan(u trial) bool {
+	if t.consistency != u.consistency {
+		return t.consistency > u.consistency
+	}
+	return t.quoted > u.quoted
+}
+
+func parseTrial(sample []byte, dialect csv.Dialect) trial {
+	t := trial{
+		dialect: dialect,
+		quoted:  countQuoted(sample, byte(dialect.Delimiter), byte(dialect.QuoteChar)),
+	}
+
+	errs := 0
+	r := csv.NewDialectReader(bytes.NewReader(sample), dialect)
+	r.OnError = func([]byte, *csv.ParseError) { errs++ }
+	rows, err := r.ReadAll()
+	if err != nil {
+		return t
+	}
+
+	counts := map[int]int{}
+	records := errs
+	for _, row := range rows {
+		if len(row) == 1 && row[0] == "" {
+			// Blank lines say nothing about the dialect.
+			continue
+		}
+		t.rows = append(t.rows, row)
+		counts[len(row)]++
+		records++
+	}
+	fields, max := 0, 0
+	for n, count := range counts {
+		if count > max || count == max && n > fields {
+			fields, max = n, count
+		}
+	}
+	if fields > 1 {
+		t.consistency = float64(max) / float64(records)
+	}
+	return t
+}
+
+func (d *detector) sniff(sample []byte) (csv.Dialect, Confidence, error) {
+	var best trial
+	for _, quote := range sniffQuotes {
+		for _, delimiter := range sniffDelimiters {
+			dialect := csv.Dialect{
+				Delimiter:      delimiter,
+				QuoteChar:      quote,
+				DoubleQuote:    csv.DoDoubleQuote,
+				LineTerminator: csv.LineTerminatorAny,
+			}
+			if guessEscaped(sample, byte(delimiter), byte(quote)) {
+				dialect.DoubleQuote = csv.NoDoubleQuote
+				dialect.EscapeChar = '\\'
+			}
+			if t := parseTrial(sample, dialect); t.betterThan(best) {
+				best = t
+			}
+		}
+	}
+	if best.consistency == 0 {
+		return csv.Dialect{}, 0, ErrNoDialect
+	}
+
+	dialect := best.dialect
+	quote := byte(dialect.QuoteChar)
+	dialect.SkipInitialSpace = dialect.Delimiter != ' ' && leadingSpaces(best.rows)
+	dialect.SetDelimiter(d.expandDelimiter(sample, dialect.Delimiter, quote))
+
+	escape := byte(0)
+	if dialect.DoubleQuote == csv.NoDoubleQuote {
+		escape = byte(dialect.EscapeChar)
+	}
+	dialect.LineTerminator = csv.DefaultLineTerminator
+	max := 0
+	for i, count := range countRowTerminators(sample, quote, escape) {
+		if count > max {
+			dialect.LineTerminator, max = rowTerminators[i], count
+		}
+	}
+
+	// Few records make for weak evidence.
+	n := float64(len(best.rows))
+	return dialect, Confidence(best.consistency * n / (n + 1)), nil
+}
+
+// guessEscaped tells whether quotes inside quoted fields are more often
+// escaped using a backslash than by doubling them.
+func guessEscaped(sample []byte, delimiter, quote byte) bool {
+	escaped := bytes.Count(sample, []byte{'\\', quote})
+
+	isBoundary := func(i int) bool {
+		if i < 0 || i >= len(sample) {
+			return true
+		}
+		c := sample[i]
+		return c == delimiter || c == '\r' || c == '\n'
+	}
+	doubled := 0
+	for i := 0; i+1 < len(sample); i++ {
+		if sample[i] != quote || sample[i+1] != quote {
+			continue
+		}
+		// An empty quoted field is not an escaped quote.
+		if !isBoundary(i-1) || !isBoundary(i+2) {
+			doubled++
+		}
+		i++
+	}
+	return escaped > doubled
+}
+
+// countQuoted counts the fields in sample starting with quote.
+func countQuoted(sample []byte, delimiter, quote byte) int {
+	n := 0
+	for i, c := range sample {
+		if c != quote {
+			continue
+		}
+		if i == 0 || sample[i-1] == delimiter || sample[i-1] == '\r' || sample[i-1] == '\n' {
+			n++
+		}
+	}
+	return n
+}
+
+// leadingSpaces tells whether most fields but the first in each row start
+// with a space.
+func leadingSpaces(rows [][]string) bool {
+	spaced, fields := 0, 0
+	for _, row := range rows {
+		for _, field := range row[1:] {
+			if strings.HasPrefix(field, " ") {
+				spaced++
+			}
+			fields++
+		}
+	}
+	return spaced > 0 && spaced*2 > fields
+}
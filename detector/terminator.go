@@ -0,0 +1,75 @@
+package detector
+
+import (
+	"bytes"
+	"io"
+)
+
+// RowTerminators describes the row terminators found in a sample.
+type RowTerminators struct {
+	// The most common row terminator. Empty if none was found.
+	Terminator string
+	// Number of occurrences of each of "\r\n", "\n" and "\r", not counting
+	// those inside quoted fields.
+	Counts map[string]int
+	// Whether more than one kind of row terminator was found.
+	Mixed bool
+}
+
+// DetectRowTerminators counts the row terminators in the first 128KB read from
+// reader, ignoring those inside fields enclosed by enclosure. Returns
+// ErrEmptySample for empty input.
+func DetectRowTerminators(reader io.Reader, enclosure byte) (RowTerminators, error) {
+	prefix, more, err := readPrefix(reader, rowTerminatorSampleSize)
+	if err != nil {
+		return RowTerminators{}, err
+	}
+	sample := sampleOf(prefix, more)
+	if more && bytes.HasSuffix(sample, []byte{'\r'}) {
+		// Might be the first half of "\r\n".
+		sample = sample[:len(sample)-1]
+	}
+
+	result := RowTerminators{Counts: map[string]int{}}
+	max, kinds := 0, 0
+	for i, count := range countRowTerminators(sample, enclosure, 0) {
+		result.Counts[rowTerminators[i]] = count
+		if count > 0 {
+			kinds++
+		}
+		if count > max {
+			result.Terminator, max = rowTerminators[i], count
+		}
+	}
+	result.Mixed = kinds > 1
+	return result, nil
+}
+
+// Row terminators recognised when counting, in the order they are matched.
+var rowTerminators = []string{"\r\n", "\n", "\r"}
+
+// countRowTerminators counts the occurrences of each of rowTerminators in
+// sample, ignoring those inside fields quoted by quote. escape is the escape
+// character, or zero if quotes are escaped by doubling them.
+func countRowTerminators(sample []byte, quote, escape byte) []int {
+	counts := make([]int, len(rowTerminators))
+	quoted := false
+	for i := 0; i < len(sample); i++ {
+		c := sample[i]
+		switch {
+		case escape != 0 && c == escape && quoted:
+			i++
+		case c == quote:
+			quoted = !quoted
+		case quoted:
+		case c == '\r' && i+1 < len(sample) && sample[i+1] == '\n':
+			counts[0]++
+			i++
+		case c == '\n':
+			counts[1]++
+		case c == '\r':
+			counts[2]++
+		}
+	}
+	return counts
+}
@@ -0,0 +1,56 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package dialect
+
+import (
+	"os"
+	"strings"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// FromEnv constructs a Dialect from environment variables named after prefix,
+// such as CSV_DELIMITER for the prefix "CSV". They are parsed and validated
+// exactly like the flags registered by a DialectBuilder:
+//
+//	PREFIX_DIALECT          -file-dialect
+//	PREFIX_DELIMITER        -fields-terminated-by
+//	PREFIX_QUOTE_CHAR       -fields-optionally-enclosed-by
+//	PREFIX_ESCAPE_CHAR      -fields-escaped-by
+//	PREFIX_DOUBLE_QUOTE     -fields-double-quoted
+//	PREFIX_LINE_TERMINATOR  -lines-terminated-by
+//	PREFIX_QUOTING          -fields-quoting
+//	PREFIX_COMMENT          -lines-starting-by
+//	PREFIX_HEADER           -file-header
+//	PREFIX_ENCODING         -file-encoding
+//
+// Unset variables take the flags' defaults, unless PREFIX_DIALECT is set.
+func FromEnv(prefix string) (*csv.Dialect, error) {
+	return fromEnv(prefix, os.LookupEnv)
+}
+
+func fromEnv(prefix string, lookup func(string) (string, bool)) (*csv.Dialect, error) {
+	return buildDialect(envSettings(prefix, lookup))
+}
+
+// envSettings returns the values of the environment variables. Variables not
+// set hold the flags' defaults.
+func envSettings(prefix string, lookup func(string) (string, bool)) (base setting, settings []setting) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	base = setting{name: prefix + dialectEnvName}
+	if value, ok := lookup(base.name); ok {
+		base.value, base.given = value, true
+	}
+	settings = make([]setting, len(dialectFlags))
+	for i, f := range dialectFlags {
+		settings[i] = setting{name: prefix + f.env, value: f.value}
+		if value, ok := lookup(settings[i].name); ok {
+			settings[i].value, settings[i].given = value, true
+		}
+	}
+	return base, settings
+}
@@ -0,0 +1,88 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package dialect
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		env  map[string]string
+		args []string // Flags giving the same dialect.
+	}{
+		{nil, nil},
+		{
+			map[string]string{
+				"CSV_DELIMITER":       `\x1f`,
+				"CSV_QUOTE_CHAR":      "'",
+				"CSV_DOUBLE_QUOTE":    "true",
+				"CSV_LINE_TERMINATOR": `\r\n`,
+				"CSV_QUOTING":         "all",
+				"CSV_COMMENT":         "#",
+				"CSV_HEADER":          "1",
+				"CSV_ENCODING":        "utf-16le",
+			},
+			[]string{
+				"-fields-terminated-by", `\x1f`,
+				"-fields-optionally-enclosed-by", "'",
+				"-fields-double-quoted",
+				"-lines-terminated-by", `\r\n`,
+				"-fields-quoting", "all",
+				"-lines-starting-by", "#",
+				"-file-header",
+				"-file-encoding", "utf-16le",
+			},
+		},
+		{
+			map[string]string{"CSV_DIALECT": "excel", "CSV_DELIMITER": ";"},
+			[]string{"-file-dialect", "excel", "-fields-terminated-by", ";"},
+		},
+	}
+	for _, test := range tests {
+		lookup := func(name string) (string, bool) {
+			value, ok := test.env[name]
+			return value, ok
+		}
+		d, err := fromEnv("CSV", lookup)
+		if err != nil {
+			t.Error("Unexpected error:", err)
+			continue
+		}
+
+		f := flag.NewFlagSet("test", flag.ContinueOnError)
+		builder := FromFlagSet(f)
+		f.Parse(test.args)
+		expected, _ := builder.Dialect()
+		if !reflect.DeepEqual(d, expected) {
+			t.Errorf("Unexpected dialect for %v: %#v", test.env, *d)
+		}
+	}
+
+	lookup := func(name string) (string, bool) {
+		return "ab", name == "CSV_ESCAPE_CHAR"
+	}
+	if _, err := fromEnv("CSV_", lookup); err == nil || !strings.Contains(err.Error(), "CSV_ESCAPE_CHAR") {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestFromEnvProcess(t *testing.T) {
+	t.Setenv("GOCSVTEST_DELIMITER", ",")
+
+	d, err := FromEnv("GOCSVTEST")
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if d.Delimiter != ',' || d.Quoting != csv.QuoteMinimal {
+		t.Errorf("Unexpected dialect: %#v", *d)
+	}
+}
@@ -9,17 +9,218 @@ package dialect
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"strconv"
 	"strings"
-	"unicode/utf8"
 
 	csv "github.com/eltorocorp/go-csv"
 )
 
+// Name of the flag, and environment variable, selecting a named dialect or
+// dialect spec to start from.
+const (
+	dialectFlagName = "file-dialect"
+	dialectEnvName  = "DIALECT"
+)
+
+// A dialectFlag is a command line flag setting part of a csv.Dialect.
+type dialectFlag struct {
+	name    string
+	env     string // Name of the environment variable, see FromEnv.
+	field   string // Name of the field in dialectFields set by the flag.
+	value   string // Default value.
+	usage   string
+	refs    []string // Names of the flags the %s verbs of usage stand for.
+	boolean bool
+	// Parses value into d.
+	apply func(d *csv.Dialect, name, value string) error
+	// Formats the field of d set by the flag. Returns "" if it's unset.
+	format func(d csv.Dialect) string
+}
+
+// dialectFlags are the flags registered by a DialectBuilder, besides the
+// dialect flag. FromEnv reads the same settings from environment variables.
+var dialectFlags = []dialectFlag{
+	{
+		name:  "fields-terminated-by",
+		env:   "DELIMITER",
+		field: "Delimiter",
+		value: "\\t",
+		usage: "string to terminate fields by",
+		apply: func(d *csv.Dialect, name, value string) error {
+			s, err := parseString(name, value)
+			d.SetDelimiter(s)
+			return err
+		},
+		format: func(d csv.Dialect) string {
+			if d.DelimiterString != "" {
+				return csv.Escape(d.DelimiterString)
+			}
+			return flagChar(d.Delimiter)
+		},
+	},
+	{
+		name:  "fields-optionally-enclosed-by",
+		env:   "QUOTE_CHAR",
+		field: "Quote",
+		value: "\"",
+		usage: "string to enclose fields with when needed; empty, along with -%s none, to never enclose fields",
+		refs:  []string{"fields-quoting"},
+		apply: func(d *csv.Dialect, name, value string) error {
+			s, err := csv.Unescape(value)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			d.SetQuote(s)
+			return nil
+		},
+		format: func(d csv.Dialect) string {
+			if d.QuoteString != "" {
+				return csv.Escape(d.QuoteString)
+			}
+			return flagChar(d.QuoteChar)
+		},
+	},
+	{
+		name:  "fields-escaped-by",
+		env:   "ESCAPE_CHAR",
+		field: "EscapeChar",
+		value: "\\",
+		usage: "character to escape special characters with; empty for none",
+		apply: func(d *csv.Dialect, name, value string) (err error) {
+			d.EscapeChar, err = parseChar(name, value, true)
+			if err == nil && d.EscapeChar == 0 {
+				d.EscapeChar = csv.NoChar
+			}
+			return err
+		},
+		format: func(d csv.Dialect) string {
+			return flagChar(d.EscapeChar)
+		},
+	},
+	{
+		name:    "fields-double-quoted",
+		env:     "DOUBLE_QUOTE",
+		field:   "DoubleQuote",
+		value:   "false",
+		usage:   "escape enclosing strings by doubling them instead of using -%s",
+		refs:    []string{"fields-escaped-by"},
+		boolean: true,
+		apply: func(d *csv.Dialect, name, value string) error {
+			double, err := parseBool(name, value)
+			d.DoubleQuote = csv.NoDoubleQuote
+			if double {
+				d.DoubleQuote = csv.DoDoubleQuote
+			}
+			return err
+		},
+		format: func(d csv.Dialect) string {
+			switch d.DoubleQuote {
+			case csv.DoDoubleQuote:
+				return "true"
+			case csv.NoDoubleQuote:
+				return "false"
+			}
+			return ""
+		},
+	},
+	{
+		name:  "lines-terminated-by",
+		env:   "LINE_TERMINATOR",
+		field: "LineTerminator",
+		value: "\\n",
+		usage: "string to terminate lines by",
+		apply: func(d *csv.Dialect, name, value string) (err error) {
+			d.LineTerminator, err = parseString(name, value)
+			return err
+		},
+		format: func(d csv.Dialect) string {
+			return csv.Escape(d.LineTerminator)
+		},
+	},
+	{
+		name:  "fields-quoting",
+		env:   "QUOTING",
+		field: "Quoting",
+		value: "minimal",
+		usage: "when to enclose fields: all, minimal, nonnumeric or none",
+		apply: func(d *csv.Dialect, name, value string) (err error) {
+			d.Quoting, err = parseQuoting(name, value)
+			return err
+		},
+		format: func(d csv.Dialect) string {
+			if d.Quoting == csv.QuoteDefault {
+				return ""
+			}
+			return d.Quoting.String()
+		},
+	},
+	{
+		name:  "lines-starting-by",
+		env:   "COMMENT",
+		field: "Comment",
+		usage: "character starting lines to skip when reading",
+		apply: func(d *csv.Dialect, name, value string) (err error) {
+			d.Comment, err = parseChar(name, value, true)
+			return err
+		},
+		format: func(d csv.Dialect) string {
+			return flagChar(d.Comment)
+		},
+	},
+	{
+		name:    "file-header",
+		env:     "HEADER",
+		field:   "Header",
+		value:   "false",
+		usage:   "whether the first line is a header",
+		boolean: true,
+		apply: func(d *csv.Dialect, name, value string) (err error) {
+			d.Header, err = parseBool(name, value)
+			return err
+		},
+		format: func(d csv.Dialect) string {
+			if d.Header {
+				return "true"
+			}
+			return ""
+		},
+	},
+	{
+		name:  "file-encoding",
+		env:   "ENCODING",
+		field: "Encoding",
+		usage: "character encoding, such as utf-8, utf-16le or windows-1252",
+		apply: func(d *csv.Dialect, name, value string) error {
+			d.Encoding = value
+			return nil
+		},
+		format: func(d csv.Dialect) string {
+			return d.Encoding
+		},
+	},
+}
+
+// FlagRegistrar is what a DialectBuilder needs from a set of flags.
+// *flag.FlagSet implements it, and so does the *FlagSet of spf13/pflag and
+// packages built on it such as cobra.
+//
+// If the registrar also has a Changed(name string) bool method, like pflag,
+// or a Visit method like *flag.FlagSet, it tells which flags were given.
+// Otherwise a flag is considered given if it differs from its default.
+type FlagRegistrar interface {
+	String(name, value, usage string) *string
+	Bool(name string, value bool, usage string) *bool
+	Parsed() bool
+}
+
 type DialectBuilder struct {
-	quoteCharString     *string
-	escapeCharString    *string
-	delimiterCharString *string
-	flagSet             *flag.FlagSet
+	prefix      string
+	flags       FlagRegistrar
+	dialectName *string
+	// Current and default value of each of dialectFlags.
+	values   []func() string
+	defaults []string
 }
 
 // Construct a CSV Dialect from command line using the `flag` package. This is
@@ -27,71 +228,172 @@ type DialectBuilder struct {
 // register other flags. Call `flag.Parse()`. A dialect can then be constructed
 // by calling `DialectBuilder.Dialect()`.
 func FromCommandLine() *DialectBuilder {
-	// flag package did not expose the CommandLine variable before Go 1.2. This
-	// is a workaround.
-	p := DialectBuilder{}
-	p.delimiterCharString = flag.String("fields-terminated-by", "\t", "character to terminate fields by")
-	p.quoteCharString = flag.String("fields-optionally-enclosed-by", "\"", "character to enclose fields with when needed")
-	p.escapeCharString = flag.String("fields-escaped-by", "\\", "character to escape special characters with")
-	p.flagSet = nil
-	return &p
+	return FromFlagSet(flag.CommandLine)
 }
 
 // Constructs a CSV Dialect from a specific flagset. Essentially the same as
 // `FromCommandLine()`, except it supports a custom FlagSet. See
 // `FromCommandLine()` for a description on how to use this one.
 func FromFlagSet(f *flag.FlagSet) *DialectBuilder {
-	p := DialectBuilder{}
-	p.delimiterCharString = f.String("fields-terminated-by", "\t", "character to terminate fields by")
-	p.quoteCharString = f.String("fields-optionally-enclosed-by", "\"", "character to enclose fields with when needed")
-	p.escapeCharString = f.String("fields-escaped-by", "\\", "character to escape special characters with")
-	p.flagSet = f
+	return FromFlagSetWithPrefix(f, "")
+}
+
+// FromFlagSetWithPrefix is like FromFlagSet, but prepends prefix to the name
+// of every flag. This makes it possible to register several dialects, such as
+// an input and an output dialect, on the same FlagSet:
+//
+//	in := dialect.FromFlagSetWithPrefix(f, "in-")
+//	out := dialect.FromFlagSetWithPrefix(f, "out-")
+func FromFlagSetWithPrefix(f *flag.FlagSet, prefix string) *DialectBuilder {
+	return FromFlagSetWithDefaults(f, prefix, csv.Dialect{})
+}
+
+// FromFlagSetWithDefaults is like FromFlagSetWithPrefix, but takes the
+// default value of each flag from defaults. Zero fields in defaults keep the
+// flag's usual default.
+func FromFlagSetWithDefaults(f *flag.FlagSet, prefix string, defaults csv.Dialect) *DialectBuilder {
+	return FromFlagRegistrar(f, prefix, defaults)
+}
+
+// FromFlagRegistrar is like FromFlagSetWithDefaults, but registers the flags
+// with any FlagRegistrar, such as a pflag.FlagSet.
+func FromFlagRegistrar(f FlagRegistrar, prefix string, defaults csv.Dialect) *DialectBuilder {
+	p := DialectBuilder{prefix: prefix, flags: f}
+	p.dialectName = f.String(prefix+dialectFlagName, "", fmt.Sprintf(
+		"named dialect or dialect spec to start from, such as %s; other flags override it when given",
+		strings.Join(csv.Dialects(), ", "),
+	))
+	for _, df := range dialectFlags {
+		def := df.value
+		if v := df.format(defaults); v != "" {
+			def = v
+		}
+		p.defaults = append(p.defaults, def)
+
+		if df.boolean {
+			b := f.Bool(prefix+df.name, def == "true", df.usageWith(prefix))
+			p.values = append(p.values, func() string { return strconv.FormatBool(*b) })
+		} else {
+			s := f.String(prefix+df.name, def, df.usageWith(prefix))
+			p.values = append(p.values, func() string { return *s })
+		}
+	}
 	return &p
 }
 
+// usageWith returns the usage of df, naming the flags it refers to with
+// prefix.
+func (df dialectFlag) usageWith(prefix string) string {
+	if len(df.refs) == 0 {
+		return df.usage
+	}
+	names := make([]interface{}, len(df.refs))
+	for i, ref := range df.refs {
+		names[i] = prefix + ref
+	}
+	return fmt.Sprintf(df.usage, names...)
+}
+
+// given tells whether the i:th of dialectFlags was given.
+func (p *DialectBuilder) given(i int) bool {
+	name := p.prefix + dialectFlags[i].name
+	switch f := p.flags.(type) {
+	case interface{ Changed(string) bool }:
+		return f.Changed(name)
+	case interface{ Visit(func(*flag.Flag)) }:
+		given := false
+		f.Visit(func(fl *flag.Flag) {
+			given = given || fl.Name == name
+		})
+		return given
+	}
+	return p.values[i]() != p.defaults[i]
+}
+
 // Construct a Dialect from a FlagSet. Make sure to parse the FlagSet before
 // calling this.
+//
+// If a named dialect or a dialect spec (see Parse) is given using -file-dialect,
+// only the flags given override it. Otherwise the flags' defaults apply as
+// well.
 func (p *DialectBuilder) Dialect() (*csv.Dialect, error) {
-	if p.flagSet != nil {
-		// flag package did not expose the CommandLine variable before Go 1.2. This
-		// is a workaround.
-		if !p.flagSet.Parsed() {
-			// Sure, could call flagSet.Parse() here. However, we don't know if the
-			// user would like to parse something else than argv. Therefor, letting the
-			// user decide.
-			return nil, errors.New("FlagSet has not been parsed before calling this function.")
-		}
-	} else if !flag.Parsed() {
-		// Sure, could call flag.Parse() here. However, we don't know if the
+	base, settings, err := p.settings()
+	if err != nil {
+		return nil, err
+	}
+	return buildDialect(base, settings)
+}
+
+// settings returns the values of the flags. Flags not given are marked as
+// such, but hold their defaults.
+func (p *DialectBuilder) settings() (base setting, settings []setting, err error) {
+	if !p.flags.Parsed() {
+		// Sure, could call flagSet.Parse() here. However, we don't know if the
 		// user would like to parse something else than argv. Therefor, letting the
 		// user decide.
-		return nil, errors.New("FlagSet has not been parsed before calling this function.")
+		return setting{}, nil, errors.New("FlagSet has not been parsed before calling this function.")
 	}
 
-	// `FlagSet`s don't have a rune type. Using string instead, but that adds
-	// some manual error checking.
-	if utf8.RuneCountInString(*p.quoteCharString) > 1 {
-		return nil, errors.New("-fields-optionally-enclosed-by can't be more than one character.")
+	base = setting{name: "-" + p.prefix + dialectFlagName, value: *p.dialectName}
+	base.given = base.value != ""
+	settings = make([]setting, len(dialectFlags))
+	for i, f := range dialectFlags {
+		settings[i] = setting{
+			name:  "-" + p.prefix + f.name,
+			value: p.values[i](),
+			given: p.given(i),
+		}
 	}
-	if utf8.RuneCountInString(*p.escapeCharString) > 1 {
-		return nil, errors.New("-fields-escaped-by can't be more than one character.")
+	return base, settings, nil
+}
+
+// A setting is the value of one of dialectFlags, or of the named dialect,
+// along with the name of the flag or environment variable it came from.
+type setting struct {
+	name, value string
+	// Whether value was given rather than being a default.
+	given bool
+}
+
+// buildDialect builds a dialect from settings, one per dialectFlags. If base
+// names a dialect or holds a spec, settings override it only if given.
+// Otherwise the defaults of settings not given apply as well.
+func buildDialect(base setting, settings []setting) (*csv.Dialect, error) {
+	if base.value == "" {
+		for i := range settings {
+			settings[i].given = true
+		}
 	}
-	if utf8.RuneCountInString(*p.quoteCharString) < 1 {
-		return nil, errors.New("-fields-optionally-enclosed-by can't be an empty string.")
+	dialect, _, err := applySettings(base, settings)
+	if err != nil {
+		return nil, err
 	}
-	if utf8.RuneCountInString(*p.escapeCharString) < 1 {
-		return nil, errors.New("-fields-escaped-by can't be an empty string.")
+	if err := dialect.Validate(); err != nil {
+		return nil, err
 	}
+	return &dialect, nil
+}
 
-	quoteChar, _, _ := strings.NewReader(*p.quoteCharString).ReadRune()
-	escapeChar, _, _ := strings.NewReader(*p.escapeCharString).ReadRune()
-	delimiterChar, _, _ := strings.NewReader(*p.delimiterCharString).ReadRune()
-	dialect := csv.Dialect{
-		Delimiter:   delimiterChar,
-		QuoteChar:   quoteChar,
-		EscapeChar:  escapeChar,
-		DoubleQuote: csv.NoDoubleQuote,
+// applySettings builds a dialect from base, if set, and the settings given.
+// Returns the names of the dialectFields that were set.
+func applySettings(base setting, settings []setting) (csv.Dialect, []string, error) {
+	var dialect csv.Dialect
+	var fields []string
+	if base.value != "" {
+		var err error
+		if dialect, err = csv.ParseDialect(base.value); err != nil {
+			return csv.Dialect{}, nil, fmt.Errorf("%s: %v", base.name, err)
+		}
+		fields = setFields(dialect)
 	}
-
-	return &dialect, nil
+	for i, f := range dialectFlags {
+		if !settings[i].given {
+			continue
+		}
+		if err := f.apply(&dialect, settings[i].name, settings[i].value); err != nil {
+			return csv.Dialect{}, nil, err
+		}
+		fields = append(fields, f.field)
+	}
+	return dialect, fields, nil
 }
@@ -0,0 +1,270 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package dialect
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+func TestDialectFromFlags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args     []string
+		expected csv.Dialect
+	}{
+		{
+			nil,
+			csv.Dialect{
+				Delimiter:      '\t',
+				QuoteChar:      '"',
+				EscapeChar:     '\\',
+				DoubleQuote:    csv.NoDoubleQuote,
+				LineTerminator: "\n",
+				Quoting:        csv.QuoteMinimal,
+			},
+		},
+		{
+			[]string{
+				"-fields-terminated-by", `\x1f`,
+				"-fields-optionally-enclosed-by", "'",
+				"-fields-escaped-by", `\\`,
+				"-fields-double-quoted",
+				"-lines-terminated-by", `\r\n`,
+				"-fields-quoting", "nonnumeric",
+				"-lines-starting-by", "#",
+				"-file-header",
+				"-file-encoding", "utf-16le",
+			},
+			csv.Dialect{
+				Delimiter:      '\x1f',
+				QuoteChar:      '\'',
+				EscapeChar:     '\\',
+				DoubleQuote:    csv.DoDoubleQuote,
+				LineTerminator: "\r\n",
+				Quoting:        csv.QuoteNonNumeric,
+				Comment:        '#',
+				Header:         true,
+				Encoding:       "utf-16le",
+			},
+		},
+		{
+			[]string{
+				"-fields-optionally-enclosed-by", "",
+				"-fields-escaped-by", "",
+				"-fields-double-quoted",
+				"-fields-quoting", "none",
+			},
+			csv.Dialect{
+				Delimiter:      '\t',
+				QuoteChar:      csv.NoChar,
+				EscapeChar:     csv.NoChar,
+				DoubleQuote:    csv.DoDoubleQuote,
+				LineTerminator: "\n",
+				Quoting:        csv.QuoteNone,
+			},
+		},
+		{
+			[]string{"-file-dialect", "excel,quoting=all", "-fields-terminated-by", ";"},
+			csv.Dialect{
+				Delimiter:      ';',
+				QuoteChar:      '"',
+				DoubleQuote:    csv.DoDoubleQuote,
+				Quoting:        csv.QuoteAll,
+				LineTerminator: "\r\n",
+			},
+		},
+	}
+	for _, test := range tests {
+		f := flag.NewFlagSet("test", flag.ContinueOnError)
+		builder := FromFlagSet(f)
+		if err := f.Parse(test.args); err != nil {
+			t.Error("Unexpected error:", err)
+			continue
+		}
+		d, err := builder.Dialect()
+		if err != nil {
+			t.Error("Unexpected error:", err)
+			continue
+		}
+		if !reflect.DeepEqual(*d, test.expected) {
+			t.Errorf("Unexpected dialect for %q: %+v", test.args, *d)
+		}
+	}
+}
+
+func TestDialectFromFlagsErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := [][]string{
+		{"-fields-escaped-by", "ab"},
+		{"-fields-optionally-enclosed-by", ""},
+		{"-fields-optionally-enclosed-by", "", "-fields-quoting", "all"},
+		{"-fields-escaped-by", ""},
+		{"-fields-terminated-by", ""},
+		{"-fields-terminated-by", `\x1`},
+		{"-fields-quoting", "sometimes"},
+		{"-lines-starting-by", "//"},
+		{"-file-dialect", "nonexistent"},
+	}
+	for _, args := range tests {
+		f := flag.NewFlagSet("test", flag.ContinueOnError)
+		builder := FromFlagSet(f)
+		f.Parse(args)
+		if _, err := builder.Dialect(); err == nil {
+			t.Errorf("Expected error for %q", args)
+		}
+	}
+}
+
+func TestDialectFromFlagsAlongsideProgramFlags(t *testing.T) {
+	t.Parallel()
+
+	// Generic names are left to the program.
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	for _, name := range []string{"dialect", "quoting", "comment", "header", "encoding"} {
+		f.Bool(name, false, "")
+	}
+	builder := FromFlagSet(f)
+	if err := f.Parse([]string{"-header", "-file-header=false"}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if d, err := builder.Dialect(); err != nil || d.Header {
+		t.Error("Unexpected dialect:", d, err)
+	}
+}
+
+func TestDialectFromFlagsWithPrefix(t *testing.T) {
+	t.Parallel()
+
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	in := FromFlagSetWithPrefix(f, "in-")
+	out := FromFlagSetWithDefaults(f, "out-", csv.Dialect{
+		Delimiter:      ',',
+		DoubleQuote:    csv.DoDoubleQuote,
+		LineTerminator: "\r\n",
+		Quoting:        csv.QuoteAll,
+	})
+	if usage := f.Lookup("in-fields-double-quoted").Usage; !strings.Contains(usage, "-in-fields-escaped-by") {
+		t.Error("Unexpected usage:", usage)
+	}
+	if def := f.Lookup("out-lines-terminated-by").DefValue; def != `\r\n` {
+		t.Errorf("Unexpected default: %q", def)
+	}
+
+	err := f.Parse([]string{"-in-fields-terminated-by", "|", "-out-fields-quoting", "none"})
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	d, err := in.Dialect()
+	if err != nil || d.Delimiter != '|' || d.LineTerminator != "\n" {
+		t.Errorf("Unexpected input dialect: %+v, %v", d, err)
+	}
+	d, err = out.Dialect()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := csv.Dialect{
+		Delimiter:      ',',
+		QuoteChar:      '"',
+		EscapeChar:     '\\',
+		DoubleQuote:    csv.DoDoubleQuote,
+		LineTerminator: "\r\n",
+		Quoting:        csv.QuoteNone,
+	}
+	if !reflect.DeepEqual(*d, expected) {
+		t.Errorf("Unexpected output dialect: %+v", *d)
+	}
+
+	f.Parse([]string{"-out-lines-starting-by", "ab"})
+	if _, err := out.Dialect(); err == nil || !strings.Contains(err.Error(), "-out-lines-starting-by") {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+// registrar mimics spf13/pflag: flags know whether they were changed, but
+// there is no Visit method.
+type registrar struct {
+	strings map[string]*string
+	bools   map[string]*bool
+	changed map[string]bool
+	parsed  bool
+}
+
+func (r *registrar) String(name, value, usage string) *string {
+	r.strings[name] = &value
+	return &value
+}
+
+func (r *registrar) Bool(name string, value bool, usage string) *bool {
+	r.bools[name] = &value
+	return &value
+}
+
+func (r *registrar) Parsed() bool {
+	return r.parsed
+}
+
+func (r *registrar) Changed(name string) bool {
+	return r.changed[name]
+}
+
+func TestDialectFromFlagRegistrar(t *testing.T) {
+	t.Parallel()
+
+	r := &registrar{
+		strings: map[string]*string{},
+		bools:   map[string]*bool{},
+		changed: map[string]bool{},
+	}
+	builder := FromFlagRegistrar(r, "", csv.Dialect{})
+	if _, err := builder.Dialect(); err == nil {
+		t.Error("Expected error before parsing")
+	}
+
+	// Given flags override the named dialect, even if set to their default.
+	*r.strings["file-dialect"] = "unix"
+	*r.strings["lines-terminated-by"] = `\n`
+	*r.bools["file-header"] = true
+	r.changed["lines-terminated-by"] = true
+	r.changed["file-header"] = true
+	r.parsed = true
+	d, err := builder.Dialect()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected, _ := csv.LookupDialect("unix")
+	expected.Header = true
+	if !reflect.DeepEqual(*d, expected) {
+		t.Errorf("Unexpected dialect: %#v", *d)
+	}
+}
+
+func TestValue(t *testing.T) {
+	t.Parallel()
+
+	var v Value
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	f.Var(&v, "dialect", "")
+	if err := f.Parse([]string{"-dialect", `excel-tab,term=lf`}); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if v.Dialect.Delimiter != '\t' || v.Dialect.LineTerminator != "\n" {
+		t.Errorf("Unexpected dialect: %#v", v.Dialect)
+	}
+	if s := v.String(); s != `delim=\t,quote=",doublequote=double,quoting=minimal,term=lf` {
+		t.Error("Unexpected string:", s)
+	}
+	if v.Type() != "dialect" {
+		t.Error("Unexpected type:", v.Type())
+	}
+	if err := v.Set("nonexistent"); err == nil {
+		t.Error("Expected error")
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package dialect
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// Parse parses a dialect spec such as "excel" or `delim=|,quote=",term=crlf`.
+// See csv.ParseDialect for the syntax.
+func Parse(spec string) (*csv.Dialect, error) {
+	d, err := csv.ParseDialect(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// flagChar formats a character field of a csv.Dialect as an escaped flag
+// value. Zero and csv.NoChar give "".
+func flagChar(r rune) string {
+	if r == 0 || r == csv.NoChar {
+		return ""
+	}
+	return csv.Escape(string(r))
+}
+
+// parseChar parses a value that must be a single character after
+// unescaping. An empty value gives zero if allowEmpty is set.
+func parseChar(name, value string, allowEmpty bool) (rune, error) {
+	s, err := csv.Unescape(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+	switch utf8.RuneCountInString(s) {
+	case 0:
+		if allowEmpty {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%s can't be an empty string.", name)
+	case 1:
+		r, _ := utf8.DecodeRuneInString(s)
+		return r, nil
+	}
+	return 0, fmt.Errorf("%s can't be more than one character.", name)
+}
+
+// parseString parses a value that must not be empty after unescaping.
+func parseString(name, value string) (string, error) {
+	s, err := csv.Unescape(value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", name, err)
+	}
+	if s == "" {
+		return "", fmt.Errorf("%s can't be an empty string.", name)
+	}
+	return s, nil
+}
+
+// parseQuoting parses the name of a quoting mode.
+func parseQuoting(name, value string) (csv.Quoting, error) {
+	q, err := csv.ParseQuoting(value)
+	if err != nil {
+		return q, fmt.Errorf("%s must be one of all, minimal, nonnumeric and none.", name)
+	}
+	return q, nil
+}
+
+// parseBool parses a boolean the way the flag package does.
+func parseBool(name, value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean.", name)
+	}
+	return b, nil
+}
@@ -0,0 +1,271 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package dialect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// Source is a layer of a Resolver that a field of the resolved dialect came
+// from.
+type Source string
+
+// Layers of a Resolver, from lowest to highest precedence.
+const (
+	SourceDefault Source = "default" // The csv package's Default* constants.
+	SourceBase    Source = "base"    // Resolver.Base.
+	SourceFile    Source = "file"    // Resolver.File.
+	SourceEnv     Source = "env"     // Resolver.EnvPrefix.
+	SourceFlag    Source = "flag"    // Resolver.Flags.
+)
+
+// A dialectField is a setting of a csv.Dialect, possibly spanning several
+// struct fields such as Delimiter and DelimiterString.
+type dialectField struct {
+	name string
+	// Copies the field from src to dst.
+	copy func(dst *csv.Dialect, src csv.Dialect)
+	// Tells whether the field is set in d.
+	isSet func(d csv.Dialect) bool
+	// Sets the field to its default. nil if the default is the zero value.
+	setDefault func(d *csv.Dialect)
+}
+
+// dialectFields are the fields a Resolver reports the Source of.
+var dialectFields = []dialectField{
+	{
+		name: "Delimiter",
+		copy: func(dst *csv.Dialect, src csv.Dialect) {
+			dst.Delimiter, dst.DelimiterString = src.Delimiter, src.DelimiterString
+		},
+		isSet:      func(d csv.Dialect) bool { return d.Delimiter != 0 || d.DelimiterString != "" },
+		setDefault: func(d *csv.Dialect) { d.Delimiter = csv.DefaultDelimiter },
+	},
+	{
+		name: "Quote",
+		copy: func(dst *csv.Dialect, src csv.Dialect) {
+			dst.QuoteChar, dst.QuoteString = src.QuoteChar, src.QuoteString
+		},
+		isSet:      func(d csv.Dialect) bool { return d.QuoteChar != 0 || d.QuoteString != "" },
+		setDefault: func(d *csv.Dialect) { d.QuoteChar = csv.DefaultQuoteChar },
+	},
+	{
+		name:  "CloseQuote",
+		copy:  func(dst *csv.Dialect, src csv.Dialect) { dst.CloseQuoteString = src.CloseQuoteString },
+		isSet: func(d csv.Dialect) bool { return d.CloseQuoteString != "" },
+	},
+	{
+		name:       "EscapeChar",
+		copy:       func(dst *csv.Dialect, src csv.Dialect) { dst.EscapeChar = src.EscapeChar },
+		isSet:      func(d csv.Dialect) bool { return d.EscapeChar != 0 },
+		setDefault: func(d *csv.Dialect) { d.EscapeChar = csv.DefaultEscapeChar },
+	},
+	{
+		name:       "DoubleQuote",
+		copy:       func(dst *csv.Dialect, src csv.Dialect) { dst.DoubleQuote = src.DoubleQuote },
+		isSet:      func(d csv.Dialect) bool { return d.DoubleQuote != csv.DoubleQuoteDefault },
+		setDefault: func(d *csv.Dialect) { d.DoubleQuote = csv.DefaultDoubleQuote },
+	},
+	{
+		name:       "Quoting",
+		copy:       func(dst *csv.Dialect, src csv.Dialect) { dst.Quoting = src.Quoting },
+		isSet:      func(d csv.Dialect) bool { return d.Quoting != csv.QuoteDefault },
+		setDefault: func(d *csv.Dialect) { d.Quoting = csv.DefaultQuoting },
+	},
+	{
+		name:       "LineTerminator",
+		copy:       func(dst *csv.Dialect, src csv.Dialect) { dst.LineTerminator = src.LineTerminator },
+		isSet:      func(d csv.Dialect) bool { return d.LineTerminator != "" },
+		setDefault: func(d *csv.Dialect) { d.LineTerminator = csv.DefaultLineTerminator },
+	},
+	{
+		name:  "SkipInitialSpace",
+		copy:  func(dst *csv.Dialect, src csv.Dialect) { dst.SkipInitialSpace = src.SkipInitialSpace },
+		isSet: func(d csv.Dialect) bool { return d.SkipInitialSpace },
+	},
+	{
+		name:  "Encoding",
+		copy:  func(dst *csv.Dialect, src csv.Dialect) { dst.Encoding = src.Encoding },
+		isSet: func(d csv.Dialect) bool { return d.Encoding != "" },
+	},
+	{
+		name:  "Comment",
+		copy:  func(dst *csv.Dialect, src csv.Dialect) { dst.Comment = src.Comment },
+		isSet: func(d csv.Dialect) bool { return d.Comment != 0 },
+	},
+	{
+		name:  "Header",
+		copy:  func(dst *csv.Dialect, src csv.Dialect) { dst.Header = src.Header },
+		isSet: func(d csv.Dialect) bool { return d.Header },
+	},
+}
+
+// setFields returns the names of the dialectFields set in d.
+func setFields(d csv.Dialect) []string {
+	var fields []string
+	for _, f := range dialectFields {
+		if f.isSet(d) {
+			fields = append(fields, f.name)
+		}
+	}
+	return fields
+}
+
+// A Resolver composes a dialect from layers, each overriding the fields it
+// sets in the ones before it: the csv package's defaults, a named base
+// dialect, a config file, environment variables and finally command line
+// flags. Layers left empty are skipped. A layer setting the quote but not the
+// closing quote resets the latter to its default.
+type Resolver struct {
+	// Named dialect or dialect spec to start from. See Parse.
+	Base string
+	// Path of a file holding a dialect as JSON or as a spec. See
+	// csv.Dialect.UnmarshalJSON and csv.ParseDialect.
+	File string
+	// Prefix of the environment variables read. See FromEnv. Only variables
+	// that are set override earlier layers.
+	EnvPrefix string
+	// Flags to read. Only flags given override earlier layers.
+	Flags *DialectBuilder
+
+	// Replaces os.LookupEnv. Used by tests.
+	lookupEnv func(string) (string, bool)
+}
+
+// A Resolution is a dialect resolved by a Resolver.
+type Resolution struct {
+	// The resolved dialect. Fields no layer set hold the csv package's
+	// defaults.
+	Dialect csv.Dialect
+	// The layer that set each field: Delimiter, Quote, CloseQuote,
+	// EscapeChar, DoubleQuote, Quoting, LineTerminator, SkipInitialSpace,
+	// Encoding, Comment and Header. Delimiter covers both Delimiter and
+	// DelimiterString of csv.Dialect, and Quote both QuoteChar and
+	// QuoteString.
+	Sources map[string]Source
+}
+
+// String lists each field along with the layer that set it, one per line.
+func (r *Resolution) String() string {
+	names := make([]string, 0, len(r.Sources))
+	for name := range r.Sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, r.Sources[name])
+	}
+	return b.String()
+}
+
+// Resolve composes the layers into a dialect and validates it.
+func (r *Resolver) Resolve() (*Resolution, error) {
+	res := &Resolution{Sources: map[string]Source{}}
+	for _, f := range dialectFields {
+		res.Sources[f.name] = SourceDefault
+		if f.setDefault != nil {
+			f.setDefault(&res.Dialect)
+		}
+	}
+	merge := func(source Source, d csv.Dialect, fields []string) {
+		// A closing quote only goes with the quote of the same layer.
+		if contains(fields, "Quote") && !contains(fields, "CloseQuote") {
+			res.Dialect.CloseQuoteString = ""
+			res.Sources["CloseQuote"] = SourceDefault
+		}
+		for _, name := range fields {
+			for _, f := range dialectFields {
+				if f.name == name {
+					f.copy(&res.Dialect, d)
+					res.Sources[name] = source
+				}
+			}
+		}
+	}
+
+	if r.Base != "" {
+		d, err := csv.ParseDialect(r.Base)
+		if err != nil {
+			return nil, err
+		}
+		merge(SourceBase, d, setFields(d))
+	}
+	if r.File != "" {
+		d, err := readDialectFile(r.File)
+		if err != nil {
+			return nil, err
+		}
+		merge(SourceFile, d, setFields(d))
+	}
+	if r.EnvPrefix != "" {
+		lookup := r.lookupEnv
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+		d, fields, err := applySettings(envSettings(r.EnvPrefix, lookup))
+		if err != nil {
+			return nil, err
+		}
+		merge(SourceEnv, d, fields)
+	}
+	if r.Flags != nil {
+		base, settings, err := r.Flags.settings()
+		if err != nil {
+			return nil, err
+		}
+		d, fields, err := applySettings(base, settings)
+		if err != nil {
+			return nil, err
+		}
+		merge(SourceFlag, d, fields)
+	}
+	if res.Sources["Quoting"] == SourceDefault && res.Dialect.QuoteChar == csv.NoChar && res.Dialect.QuoteString == "" {
+		// Like csv.Dialect does, default to not quoting without a quote.
+		res.Dialect.Quoting = csv.QuoteNone
+	}
+
+	if err := res.Dialect.Validate(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readDialectFile reads a dialect stored as JSON or as a spec.
+func readDialectFile(path string) (csv.Dialect, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return csv.Dialect{}, err
+	}
+	var d csv.Dialect
+	b = bytes.TrimSpace(b)
+	if bytes.HasPrefix(b, []byte("{")) {
+		err = json.Unmarshal(b, &d)
+	} else {
+		err = d.UnmarshalText(b)
+	}
+	if err != nil {
+		return csv.Dialect{}, fmt.Errorf("%s: %v", path, err)
+	}
+	return d, nil
+}
+
+// contains tells whether names holds name.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
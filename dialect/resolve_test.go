@@ -0,0 +1,177 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package dialect
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+func TestResolver(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "dialect.json")
+	if err := os.WriteFile(file, []byte(`{"quote": "'", "comment": "#"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"CSV_COMMENT": ";", "CSV_HEADER": "true"}
+
+	f := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := FromFlagSet(f)
+	if err := f.Parse([]string{"-file-header=false", "-fields-quoting", "all"}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := Resolver{
+		Base:      "excel",
+		File:      file,
+		EnvPrefix: "CSV",
+		Flags:     flags,
+		lookupEnv: func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		},
+	}
+	res, err := r.Resolve()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+
+	expected := csv.Dialect{
+		Delimiter:      ',',
+		QuoteChar:      '\'',
+		EscapeChar:     csv.DefaultEscapeChar,
+		DoubleQuote:    csv.DoDoubleQuote,
+		Quoting:        csv.QuoteAll,
+		LineTerminator: "\r\n",
+		Comment:        ';',
+		Header:         false,
+	}
+	if !reflect.DeepEqual(res.Dialect, expected) {
+		t.Errorf("Unexpected dialect: %+v", res.Dialect)
+	}
+	sources := map[string]Source{
+		"Delimiter":        SourceBase,
+		"Quote":            SourceFile,
+		"CloseQuote":       SourceDefault,
+		"EscapeChar":       SourceDefault,
+		"DoubleQuote":      SourceBase,
+		"Quoting":          SourceFlag,
+		"LineTerminator":   SourceBase,
+		"SkipInitialSpace": SourceDefault,
+		"Encoding":         SourceDefault,
+		"Comment":          SourceEnv,
+		"Header":           SourceFlag,
+	}
+	if !reflect.DeepEqual(res.Sources, sources) {
+		t.Errorf("Unexpected sources:\n%s", res)
+	}
+}
+
+func TestResolverQuoteResetsCloseQuote(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "dialect.json")
+	if err := os.WriteFile(file, []byte(`{"quote": "<<", "close_quote": ">>"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"CSV_QUOTE_CHAR": "'"}
+
+	r := Resolver{
+		File:      file,
+		EnvPrefix: "CSV",
+		lookupEnv: func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		},
+	}
+	res, err := r.Resolve()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res.Dialect.QuoteChar != '\'' || res.Dialect.CloseQuoteString != "" {
+		t.Errorf("Unexpected dialect: %+v", res.Dialect)
+	}
+	if res.Sources["Quote"] != SourceEnv || res.Sources["CloseQuote"] != SourceDefault {
+		t.Errorf("Unexpected sources:\n%s", res)
+	}
+
+	// Both set by the same layer are kept.
+	delete(env, "CSV_QUOTE_CHAR")
+	if res, err = r.Resolve(); err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res.Dialect.QuoteString != "<<" || res.Dialect.CloseQuoteString != ">>" || res.Sources["CloseQuote"] != SourceFile {
+		t.Errorf("Unexpected resolution: %+v\n%s", res.Dialect, res)
+	}
+}
+
+func TestResolverDefaults(t *testing.T) {
+	t.Parallel()
+
+	res, err := (&Resolver{}).Resolve()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	expected := csv.Dialect{
+		Delimiter:      csv.DefaultDelimiter,
+		QuoteChar:      csv.DefaultQuoteChar,
+		EscapeChar:     csv.DefaultEscapeChar,
+		DoubleQuote:    csv.DefaultDoubleQuote,
+		Quoting:        csv.DefaultQuoting,
+		LineTerminator: csv.DefaultLineTerminator,
+	}
+	if !reflect.DeepEqual(res.Dialect, expected) {
+		t.Errorf("Unexpected dialect: %+v", res.Dialect)
+	}
+	for name, source := range res.Sources {
+		if source != SourceDefault {
+			t.Errorf("Unexpected source of %s: %s", name, source)
+		}
+	}
+}
+
+func TestResolverNoQuote(t *testing.T) {
+	t.Parallel()
+
+	res, err := (&Resolver{Base: "delim=tab,quote=,escape="}).Resolve()
+	if err != nil {
+		t.Fatal("Unexpected error:", err)
+	}
+	if res.Dialect.QuoteChar != csv.NoChar || res.Dialect.EscapeChar != csv.NoChar || res.Dialect.Quoting != csv.QuoteNone {
+		t.Errorf("Unexpected dialect: %+v", res.Dialect)
+	}
+}
+
+func TestResolverErrors(t *testing.T) {
+	t.Parallel()
+
+	spec := filepath.Join(t.TempDir(), "dialect.spec")
+	if err := os.WriteFile(spec, []byte("delim=|,quote=|\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"CSV_QUOTING": "sometimes"}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []Resolver{
+		{Base: "no-such-dialect"},
+		{File: filepath.Join(t.TempDir(), "missing")},
+		{File: spec},
+		{EnvPrefix: "CSV", lookupEnv: lookup},
+		{Flags: FromFlagSet(flag.NewFlagSet("test", flag.ContinueOnError))},
+	}
+	for _, r := range tests {
+		if _, err := r.Resolve(); err == nil {
+			t.Errorf("Expected error resolving %+v", r)
+		}
+	}
+}
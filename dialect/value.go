@@ -0,0 +1,42 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package dialect
+
+import (
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// Value is a flag.Value setting a whole dialect from a single flag, given as a
+// named dialect or a dialect spec (see Parse):
+//
+//	var input dialect.Value
+//	flag.Var(&input, "dialect", "input dialect, such as excel")
+//
+// It also implements the Value interface of spf13/pflag.
+type Value struct {
+	Dialect csv.Dialect
+}
+
+// String returns the dialect as a spec.
+func (v *Value) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.Dialect.String()
+}
+
+// Set parses s as a named dialect or dialect spec.
+func (v *Value) Set(s string) error {
+	d, err := csv.ParseDialect(s)
+	if err != nil {
+		return err
+	}
+	v.Dialect = d
+	return nil
+}
+
+// Type returns the name of the value's type, as shown by pflag.
+func (v *Value) Type() string {
+	return "dialect"
+}
@@ -0,0 +1,267 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Values Dialect.Encoding can take. Names are case insensitive.
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingLatin1      = "iso-8859-1"
+	EncodingWindows1252 = "windows-1252"
+)
+
+var (
+	// ErrUnknownEncoding is returned when reading or writing using an
+	// unsupported Dialect.Encoding.
+	ErrUnknownEncoding = errors.New("csv: unknown encoding")
+	// ErrUnencodable is returned when writing a character that the
+	// Dialect.Encoding can't represent.
+	ErrUnencodable = errors.New("csv: character can't be encoded")
+)
+
+// Characters 0x80 to 0x9F of Windows-1252. Zero where undefined.
+var windows1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+// An encoding converts between runes and the bytes of a character encoding.
+type encoding struct {
+	// Reads the next rune.
+	decode func(r *bufio.Reader) (rune, error)
+	// Appends the encoding of r to p. Returns false if r can't be encoded.
+	encode func(p []byte, r rune) ([]byte, bool)
+	// Byte order mark skipped when decoding.
+	bom string
+}
+
+func lookupEncoding(name string) (*encoding, error) {
+	switch strings.ToLower(name) {
+	case EncodingUTF8, "utf8":
+		return &encoding{decodeUTF8, encodeUTF8, "\xef\xbb\xbf"}, nil
+	case EncodingUTF16LE:
+		return &encoding{decodeUTF16(false), encodeUTF16(false), "\xff\xfe"}, nil
+	case EncodingUTF16BE:
+		return &encoding{decodeUTF16(true), encodeUTF16(true), "\xfe\xff"}, nil
+	case EncodingLatin1, "latin1", "latin-1":
+		return &encoding{decodeLatin1, encodeLatin1, ""}, nil
+	case EncodingWindows1252, "cp1252":
+		return &encoding{decodeWindows1252, encodeWindows1252, ""}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownEncoding, name)
+}
+
+func decodeUTF8(r *bufio.Reader) (rune, error) {
+	c, _, err := r.ReadRune()
+	return c, err
+}
+
+func encodeUTF8(p []byte, r rune) ([]byte, bool) {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(p, buf[:n]...), true
+}
+
+func decodeUTF16(bigEndian bool) func(r *bufio.Reader) (rune, error) {
+	readUnit := func(r *bufio.Reader) (rune, error) {
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return utf8.RuneError, nil
+			}
+			return 0, err
+		}
+		if bigEndian {
+			return rune(b[0])<<8 | rune(b[1]), nil
+		}
+		return rune(b[1])<<8 | rune(b[0]), nil
+	}
+	return func(r *bufio.Reader) (rune, error) {
+		c, err := readUnit(r)
+		if err != nil || !utf16.IsSurrogate(c) {
+			return c, err
+		}
+		// Only peek at the second half in case it's not a valid one.
+		b, err := r.Peek(2)
+		if err != nil {
+			return utf8.RuneError, nil
+		}
+		low := rune(b[1])<<8 | rune(b[0])
+		if bigEndian {
+			low = rune(b[0])<<8 | rune(b[1])
+		}
+		if decoded := utf16.DecodeRune(c, low); decoded != utf8.RuneError {
+			r.Discard(2)
+			return decoded, nil
+		}
+		return utf8.RuneError, nil
+	}
+}
+
+func encodeUTF16(bigEndian bool) func(p []byte, r rune) ([]byte, bool) {
+	return func(p []byte, r rune) ([]byte, bool) {
+		for _, unit := range utf16.Encode([]rune{r}) {
+			if bigEndian {
+				p = append(p, byte(unit>>8), byte(unit))
+			} else {
+				p = append(p, byte(unit), byte(unit>>8))
+			}
+		}
+		return p, true
+	}
+}
+
+func decodeLatin1(r *bufio.Reader) (rune, error) {
+	c, err := r.ReadByte()
+	return rune(c), err
+}
+
+func encodeLatin1(p []byte, r rune) ([]byte, bool) {
+	if r > 0xFF {
+		return p, false
+	}
+	return append(p, byte(r)), true
+}
+
+func decodeWindows1252(r *bufio.Reader) (rune, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if c >= 0x80 && c < 0xA0 && windows1252[c-0x80] != 0 {
+		return windows1252[c-0x80], nil
+	}
+	return rune(c), nil
+}
+
+func encodeWindows1252(p []byte, r rune) ([]byte, bool) {
+	for i, c := range windows1252 {
+		if c == r && c != 0 {
+			return append(p, byte(0x80+i)), true
+		}
+	}
+	if r >= 0x80 && r < 0xA0 && windows1252[r-0x80] != 0 {
+		// Taken by one of the characters above.
+		return p, false
+	}
+	return encodeLatin1(p, r)
+}
+
+// newDecoder returns a reader converting the named encoding read from r to
+// UTF-8. An empty name means r is read as is.
+func newDecoder(r io.Reader, name string) io.Reader {
+	if name == "" {
+		return r
+	}
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return errReadWriter{err}
+	}
+	return &decoder{r: bufio.NewReader(r), enc: enc}
+}
+
+type decoder struct {
+	r       *bufio.Reader
+	enc     *encoding
+	pending []byte // Decoded bytes that did not fit in the last Read.
+	started bool
+	err     error
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	if !d.started {
+		d.started = true
+		if b, _ := d.r.Peek(len(d.enc.bom)); d.enc.bom != "" && string(b) == d.enc.bom {
+			d.r.Discard(len(b))
+		}
+	}
+
+	n := copy(p, d.pending)
+	d.pending = d.pending[n:]
+	for n < len(p) && d.err == nil {
+		var c rune
+		if c, d.err = d.enc.decode(d.r); d.err != nil {
+			break
+		}
+		var buf [utf8.UTFMax]byte
+		size := utf8.EncodeRune(buf[:], c)
+		copied := copy(p[n:], buf[:size])
+		d.pending = append(d.pending, buf[copied:size]...)
+		n += copied
+	}
+	if n > 0 {
+		return n, nil
+	}
+	return 0, d.err
+}
+
+// newEncoder returns a writer converting UTF-8 written to it to the named
+// encoding before writing it to w. An empty name means nothing is converted.
+func newEncoder(w io.Writer, name string) io.Writer {
+	if name == "" {
+		return w
+	}
+	enc, err := lookupEncoding(name)
+	if err != nil {
+		return errReadWriter{err}
+	}
+	return &encoder{w: w, enc: enc}
+}
+
+type encoder struct {
+	w       io.Writer
+	enc     *encoding
+	pending []byte // Start of a rune split between two writes.
+	buf     []byte
+}
+
+func (e *encoder) Write(p []byte) (int, error) {
+	written := len(p)
+	if len(e.pending) > 0 {
+		p = append(e.pending, p...)
+		e.pending = nil
+	}
+
+	e.buf = e.buf[:0]
+	for len(p) > 0 {
+		if !utf8.FullRune(p) {
+			e.pending = append(e.pending, p...)
+			break
+		}
+		c, size := utf8.DecodeRune(p)
+		var ok bool
+		if e.buf, ok = e.enc.encode(e.buf, c); !ok {
+			return 0, fmt.Errorf("%w: %q", ErrUnencodable, c)
+		}
+		p = p[size:]
+	}
+	if _, err := e.w.Write(e.buf); err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// errReadWriter fails every read and write with err.
+type errReadWriter struct {
+	err error
+}
+
+func (e errReadWriter) Read([]byte) (int, error) {
+	return 0, e.err
+}
+
+func (e errReadWriter) Write([]byte) (int, error) {
+	return 0, e.err
+}
@@ -0,0 +1,285 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// A ColumnSpan is the position of a column in a fixed-width file, counted in
+// terminal cells from the start of the line like FixedWidthColumn widths: see
+// RuneWidth. End is exclusive. An End of zero or less means the column
+// extends to the end of the line. A wide character belongs to the column its
+// first cell is in.
+type ColumnSpan struct {
+	Start, End int
+}
+
+// A FixedWidthReader reads records from a file where each field occupies the
+// same columns on every line, padded using spaces. It reads the same records
+// a Reader would read from the equivalent CSV file.
+//
+// Can be created by calling NewFixedWidthReader.
+type FixedWidthReader struct {
+	r     *bufio.Reader
+	spans []ColumnSpan
+}
+
+// NewFixedWidthReader creates a reader splitting each line read from r into
+// one field per span. Spaces surrounding a field are removed, as are "\r\n"
+// and "\n" line terminators. Blank lines are skipped. Spans beyond the end of a
+// line give empty fields.
+func NewFixedWidthReader(r io.Reader, spans []ColumnSpan) *FixedWidthReader {
+	return &FixedWidthReader{
+		r:     bufio.NewReader(r),
+		spans: spans,
+	}
+}
+
+// Read reads one record from r. The record is a slice of strings with each
+// string representing one field.
+func (r *FixedWidthReader) Read() ([]string, error) {
+	for {
+		line, err := r.r.ReadString('\n')
+		if line == "" && err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return r.split(line), nil
+	}
+}
+
+func (r *FixedWidthReader) split(line string) []string {
+	offsets := cellOffsets(line)
+	width := len(offsets) - 1
+	record := make([]string, len(r.spans))
+	for i, span := range r.spans {
+		start, end := span.Start, span.End
+		if end <= 0 || end > width {
+			end = width
+		}
+		if start < end {
+			record[i] = strings.TrimSpace(line[offsets[start]:offsets[end]])
+		}
+	}
+	return record
+}
+
+// cellOffsets returns, for each terminal cell of line and for its end, the
+// byte offset of the first character starting at or after that cell.
+// Characters of zero width stay with the preceding one.
+func cellOffsets(line string) []int {
+	offsets := []int{0}
+	cell := 0
+	for i, c := range line {
+		w := RuneWidth(c)
+		if w == 0 {
+			continue
+		}
+		for len(offsets) <= cell {
+			offsets = append(offsets, i)
+		}
+		cell += w
+	}
+	for len(offsets) <= cell {
+		offsets = append(offsets, len(line))
+	}
+	return offsets
+}
+
+// ReadAll reads all the remaining records from r. Each record is a slice of
+// fields. A successful call returns err == nil, not err == EOF. Because
+// ReadAll is defined to read until EOF, it does not treat end of file as an
+// error to be reported.
+func (r *FixedWidthReader) ReadAll() ([][]string, error) {
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// Alignment of a value within a fixed-width column.
+type Alignment int
+
+// Alignments available to FixedWidthColumn.
+const (
+	AlignLeft Alignment = iota
+	AlignRight
+)
+
+// Overflow tells what to do with a value wider than its fixed-width column.
+type Overflow int
+
+// Overflow policies available to FixedWidthColumn.
+const (
+	// Fail the write with ErrFieldOverflow.
+	OverflowError Overflow = iota
+	// Cut the value to fit.
+	OverflowTruncate
+)
+
+// ErrFieldOverflow is returned when writing a value wider than its column
+// using OverflowError.
+var ErrFieldOverflow = errors.New("csv: field wider than column")
+
+// A FixedWidthColumn describes how a FixedWidthWriter writes a column.
+type FixedWidthColumn struct {
+	// Width of the column, counted in terminal cells: East Asian wide
+	// characters take two and combining marks none.
+	Width    int
+	Align    Alignment
+	Pad      rune // Defaults to ' '.
+	Overflow Overflow
+}
+
+// A FixedWidthWriter writes records as lines where each field occupies the
+// same columns.
+//
+// Can be created by calling NewFixedWidthWriter.
+type FixedWidthWriter struct {
+	// String terminating each line. Defaults to DefaultLineTerminator.
+	LineTerminator string
+
+	w       *bufio.Writer
+	columns []FixedWidthColumn
+	line    strings.Builder
+}
+
+// NewFixedWidthWriter creates a writer writing one field per column to w.
+func NewFixedWidthWriter(w io.Writer, columns []FixedWidthColumn) *FixedWidthWriter {
+	return &FixedWidthWriter{
+		LineTerminator: DefaultLineTerminator,
+		w:              bufio.NewWriter(w),
+		columns:        columns,
+	}
+}
+
+// Write writes a single record to w, padding each field to the width of its
+// column. Missing fields are written as blank. Nothing is written if the
+// record has more fields than there are columns or a field overflows its
+// column.
+func (w *FixedWidthWriter) Write(record []string) error {
+	if len(record) > len(w.columns) {
+		return fmt.Errorf("csv: record has %d fields, expected at most %d", len(record), len(w.columns))
+	}
+	w.line.Reset()
+	for i, column := range w.columns {
+		field := ""
+		if i < len(record) {
+			field = record[i]
+		}
+		if err := w.writeField(field, column); err != nil {
+			return fmt.Errorf("%w: field %d", err, i+1)
+		}
+	}
+	w.line.WriteString(w.LineTerminator)
+	_, err := w.w.WriteString(w.line.String())
+	return err
+}
+
+func (w *FixedWidthWriter) writeField(field string, column FixedWidthColumn) error {
+	width := StringWidth(field)
+	if width > column.Width {
+		if column.Overflow != OverflowTruncate {
+			return ErrFieldOverflow
+		}
+		field, width = truncateWidth(field, column.Width)
+	}
+
+	pad := column.Pad
+	if pad == 0 {
+		pad = ' '
+	}
+	padding := strings.Repeat(string(pad), column.Width-width)
+	if column.Align == AlignRight {
+		w.line.WriteString(padding)
+		w.line.WriteString(field)
+	} else {
+		w.line.WriteString(field)
+		w.line.WriteString(padding)
+	}
+	return nil
+}
+
+// WriteAll writes multiple records to w using Write and then calls Flush.
+func (w *FixedWidthWriter) WriteAll(records [][]string) error {
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (w *FixedWidthWriter) Error() error {
+	_, err := w.w.Write(nil)
+	return err
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+// To check if an error occurred during the Flush, call Error.
+func (w *FixedWidthWriter) Flush() {
+	w.w.Flush()
+}
+
+// RuneWidth returns the number of terminal cells r occupies: two for East
+// Asian wide and fullwidth characters, zero for combining marks and format
+// characters and one otherwise.
+func RuneWidth(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case r >= 0x1100 && r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF && r != 0x303F, // CJK ... Yi
+		r >= 0xAC00 && r <= 0xD7A3,                // Hangul Syllables
+		r >= 0xF900 && r <= 0xFAFF,                // CJK Compatibility Ideographs
+		r >= 0xFE30 && r <= 0xFE4F,                // CJK Compatibility Forms
+		r >= 0xFF00 && r <= 0xFF60,                // Fullwidth Forms
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, // Pictographs and Emoticons
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD: // CJK Extensions
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the number of terminal cells s occupies. See RuneWidth.
+func StringWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// truncateWidth cuts s to at most max terminal cells, never splitting a wide
+// character.
+func truncateWidth(s string, max int) (string, int) {
+	width := 0
+	for i, r := range s {
+		w := RuneWidth(r)
+		if width+w > max {
+			return s[:i], width
+		}
+		width += w
+	}
+	return s, width
+}
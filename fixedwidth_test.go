@@ -0,0 +1,128 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFixedWidthReader(t *testing.T) {
+	t.Parallel()
+
+	input := "  1 héllo  x\n\n 22 wörld\r\n333\n"
+	r := NewFixedWidthReader(strings.NewReader(input), []ColumnSpan{{0, 3}, {3, 10}, {10, 0}})
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	expected := [][]string{
+		{"1", "héllo", "x"},
+		{"22", "wörld", ""},
+		{"333", "", ""},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Error("Unexpected output:", data)
+	}
+}
+
+func TestFixedWidthWriter(t *testing.T) {
+	t.Parallel()
+
+	b := new(bytes.Buffer)
+	w := NewFixedWidthWriter(b, []FixedWidthColumn{
+		{Width: 6, Align: AlignRight, Pad: '0'},
+		{Width: 6, Overflow: OverflowTruncate},
+		{Width: 4},
+	})
+	w.LineTerminator = "\r\n"
+	err := w.WriteAll([][]string{
+		{"1", "héllo", "x"},
+		{"22", "x日本語", "é"},
+		{"333"},
+	})
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	expected := "000001héllo x   \r\n" +
+		"000022x日本 é   \r\n" +
+		"000333          \r\n"
+	if s := b.String(); s != expected {
+		t.Errorf("Unexpected output: %q", s)
+	}
+
+	// Reads back using the same columns.
+	r := NewFixedWidthReader(strings.NewReader("000001héllo x   \n"), []ColumnSpan{{0, 6}, {6, 12}, {12, 0}})
+	if data, _ := r.Read(); !reflect.DeepEqual(data, []string{"000001", "héllo", "x"}) {
+		t.Error("Unexpected output:", data)
+	}
+}
+
+func TestFixedWidthRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"日本", "x"},
+		{"e\u0301", "한"},
+		{"ab", "Ａ"},
+	}
+	b := new(bytes.Buffer)
+	w := NewFixedWidthWriter(b, []FixedWidthColumn{{Width: 4}, {Width: 2}})
+	if err := w.WriteAll(records); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	r := NewFixedWidthReader(b, []ColumnSpan{{0, 4}, {4, 6}})
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, records) {
+		t.Errorf("Unexpected output: %q", data)
+	}
+
+	// A wide character belongs to the column its first cell is in.
+	r = NewFixedWidthReader(strings.NewReader("a日b\n"), []ColumnSpan{{0, 2}, {2, 0}})
+	if data, _ := r.Read(); !reflect.DeepEqual(data, []string{"a日", "b"}) {
+		t.Errorf("Unexpected output: %q", data)
+	}
+}
+
+func TestFixedWidthWriterOverflow(t *testing.T) {
+	t.Parallel()
+
+	b := new(bytes.Buffer)
+	w := NewFixedWidthWriter(b, []FixedWidthColumn{{Width: 2}, {Width: 2}})
+	if err := w.Write([]string{"a", "日本"}); !errors.Is(err, ErrFieldOverflow) {
+		t.Error("Unexpected error:", err)
+	}
+	if err := w.Write([]string{"a", "b", "c"}); err == nil {
+		t.Error("Expected error for too many fields")
+	}
+	w.Flush()
+	if b.Len() != 0 {
+		t.Errorf("Unexpected output: %q", b.String())
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"":        0,
+		"abc":     3,
+		"日本":      4,
+		"ｱ":       1,
+		"Ａ":       2,
+		"e\u0301": 1,
+		"한국":      4,
+	}
+	for s, expected := range tests {
+		if width := StringWidth(s); width != expected {
+			t.Errorf("Unexpected width of %q: %d", s, width)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package interfaces
+
+import (
+	"context"
+	"io"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// Optional interfaces a Reader or Writer may implement. Use type assertions
+// to find out whether one does:
+//
+//	if p, ok := r.(FieldPositioner); ok {
+//		line, column := p.FieldPos(0)
+//	}
+//
+// The go-csv readers and writers implement them, and so do encoding/csv
+// readers and writers wrapped by WrapStdReader and WrapStdWriter.
+
+// A FieldPositioner tells where in the input the record last read is.
+// Conforms to encoding/csv Reader.
+type FieldPositioner interface {
+	// FieldPos returns the 1-indexed line and column where the field with the
+	// given index in the record most recently read starts.
+	FieldPos(field int) (line, column int)
+
+	// InputOffset returns the input byte offset of the end of the record most
+	// recently read.
+	InputOffset() int64
+}
+
+// A HeaderReader reads the header of a file separately from its records.
+type HeaderReader interface {
+	// Header returns the header record, reading it first if needed. Returns
+	// nil if the file has no header.
+	Header() (header []string, err error)
+}
+
+// A RecordWriterTo writes records read from it to a Writer, much like an
+// io.WriterTo. See CopyRecords.
+type RecordWriterTo interface {
+	// WriteRecordsTo writes all remaining records to w and returns how many
+	// were written. Reaching the end of the input is not an error.
+	WriteRecordsTo(w interface{ Write(record []string) error }) (n int, err error)
+}
+
+// A ContextReader reads records unless a context is done.
+type ContextReader interface {
+	// ReadContext is like Read, but returns ctx.Err() if ctx is done.
+	ReadContext(ctx context.Context) (record []string, err error)
+}
+
+// A DialectProvider tells the dialect it reads or writes.
+type DialectProvider interface {
+	// Dialect returns the dialect, with defaults applied.
+	Dialect() csv.Dialect
+}
+
+// CopyRecords writes the records read from r to w until the end of the input
+// and returns how many were written. Uses RecordWriterTo if r implements it.
+// Does not flush w.
+func CopyRecords(w Writer, r Reader) (n int, err error) {
+	if wt, ok := r.(RecordWriterTo); ok {
+		return wt.WriteRecordsTo(w)
+	}
+	return copyRecords(w, r)
+}
+
+// copyRecords is CopyRecords reading one record at a time.
+func copyRecords(w interface{ Write(record []string) error }, r Reader) (n int, err error) {
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := w.Write(record); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
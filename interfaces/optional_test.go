@@ -0,0 +1,135 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package interfaces
+
+import (
+	"bytes"
+	"context"
+	stdcsv "encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+func TestOptionalInterfaces(t *testing.T) {
+	t.Parallel()
+
+	var positioner FieldPositioner
+	positioner = csv.NewReader(new(bytes.Buffer))
+	positioner = csv.NewStdReader(new(bytes.Buffer))
+	positioner = WrapStdReader(stdcsv.NewReader(new(bytes.Buffer)))
+
+	var header HeaderReader
+	header = csv.NewReader(new(bytes.Buffer))
+
+	var writerTo RecordWriterTo
+	writerTo = csv.NewReader(new(bytes.Buffer))
+	writerTo = csv.NewStdReader(new(bytes.Buffer))
+	writerTo = WrapStdReader(stdcsv.NewReader(new(bytes.Buffer)))
+
+	var contextReader ContextReader
+	contextReader = csv.NewReader(new(bytes.Buffer))
+	contextReader = csv.NewStdReader(new(bytes.Buffer))
+	contextReader = WrapStdReader(stdcsv.NewReader(new(bytes.Buffer)))
+
+	var provider DialectProvider
+	provider = csv.NewReader(new(bytes.Buffer))
+	provider = csv.NewStdReader(new(bytes.Buffer))
+	provider = WrapStdReader(stdcsv.NewReader(new(bytes.Buffer)))
+	provider = csv.NewWriter(new(bytes.Buffer))
+	provider = csv.NewStdWriter(new(bytes.Buffer))
+	provider = WrapStdWriter(stdcsv.NewWriter(new(bytes.Buffer)))
+
+	// To get rid of compile-time warning that these variables are not used.
+	_, _, _, _, _ = positioner, header, writerTo, contextReader, provider
+}
+
+func TestFieldPositioner(t *testing.T) {
+	t.Parallel()
+
+	input := "a,bb\n\"c\nd\",e\n"
+	readers := []Reader{
+		csv.NewDialectReader(strings.NewReader(input), csv.Dialect{Delimiter: ','}),
+		csv.NewStdReader(strings.NewReader(input)),
+		WrapStdReader(stdcsv.NewReader(strings.NewReader(input))),
+	}
+	expected := [][][2]int{{{1, 1}, {1, 3}}, {{2, 1}, {3, 4}}}
+	for _, r := range readers {
+		p := r.(FieldPositioner)
+		for i, positions := range expected {
+			record, err := r.Read()
+			if err != nil {
+				t.Error("Unexpected error:", err)
+				continue
+			}
+			for field := range record {
+				if line, column := p.FieldPos(field); [2]int{line, column} != positions[field] {
+					t.Errorf("Unexpected position of field %d of record %d using %T: %d:%d", field, i, r, line, column)
+				}
+			}
+		}
+		if offset := p.InputOffset(); offset != int64(len(input)) {
+			t.Errorf("Unexpected offset using %T: %d", r, offset)
+		}
+	}
+}
+
+func TestCopyRecords(t *testing.T) {
+	t.Parallel()
+
+	input := "a,b\nc,d\n"
+	readers := []Reader{
+		csv.NewDialectReader(strings.NewReader(input), csv.Dialect{Delimiter: ','}),
+		WrapStdReader(stdcsv.NewReader(strings.NewReader(input))),
+		stdcsv.NewReader(strings.NewReader(input)),
+	}
+	for _, r := range readers {
+		b := new(bytes.Buffer)
+		w := stdcsv.NewWriter(b)
+		n, err := CopyRecords(w, r)
+		w.Flush()
+		if err != nil || n != 2 || b.String() != input {
+			t.Errorf("Unexpected result using %T: %d, %q, %v", r, n, b.String(), err)
+		}
+	}
+}
+
+func TestContextReader(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	readers := []ContextReader{
+		csv.NewReader(strings.NewReader("a\nb\n")),
+		WrapStdReader(stdcsv.NewReader(strings.NewReader("a\nb\n"))),
+	}
+	for _, r := range readers {
+		if record, err := r.ReadContext(ctx); err != nil || !reflect.DeepEqual(record, []string{"a"}) {
+			t.Errorf("Unexpected result using %T: %q, %v", r, record, err)
+		}
+	}
+	cancel()
+	for _, r := range readers {
+		if _, err := r.ReadContext(ctx); err != context.Canceled {
+			t.Errorf("Unexpected error using %T: %v", r, err)
+		}
+	}
+}
+
+func TestDialectProvider(t *testing.T) {
+	t.Parallel()
+
+	std := stdcsv.NewReader(nil)
+	std.Comma = ';'
+	if d := WrapStdReader(std).Dialect(); d.Delimiter != ';' {
+		t.Errorf("Unexpected dialect: %#v", d)
+	}
+	if d := csv.NewDialectReader(nil, csv.Dialect{Delimiter: '|'}).Dialect(); d.Delimiter != '|' || d.QuoteChar != csv.DefaultQuoteChar {
+		t.Errorf("Unexpected dialect: %#v", d)
+	}
+	if d := csv.NewWriter(nil).Dialect(); d.LineTerminator != csv.DefaultLineTerminator {
+		t.Errorf("Unexpected dialect: %#v", d)
+	}
+}
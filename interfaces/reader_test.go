@@ -17,6 +17,8 @@ func TestReaderInterface(t *testing.T) {
 	iface = csv.NewReader(new(bytes.Buffer))
 	iface = csv.NewDialectReader(new(bytes.Buffer), csv.Dialect{})
 	iface = csv.NewReader(new(bytes.Buffer))
+	iface = csv.NewFixedWidthReader(new(bytes.Buffer), nil)
+	iface = csv.NewStdReader(new(bytes.Buffer))
 
 	// To get rid of compile-time warning that this variable is not used.
 	iface.Read()
@@ -0,0 +1,64 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package interfaces
+
+import (
+	"context"
+	stdcsv "encoding/csv"
+
+	csv "github.com/eltorocorp/go-csv"
+)
+
+// A WrappedStdReader wraps an encoding/csv Reader, adding the optional
+// interfaces it can implement: FieldPositioner, RecordWriterTo, ContextReader
+// and DialectProvider. HeaderReader is left out since encoding/csv has no
+// notion of headers.
+//
+// Can be created by calling WrapStdReader.
+type WrappedStdReader struct {
+	*stdcsv.Reader
+}
+
+// WrapStdReader wraps r.
+func WrapStdReader(r *stdcsv.Reader) WrappedStdReader {
+	return WrappedStdReader{r}
+}
+
+// ReadContext is like Read, but returns ctx.Err() instead of reading if ctx
+// is done.
+func (r WrappedStdReader) ReadContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.Read()
+}
+
+// WriteRecordsTo writes the remaining records read from r to w and returns
+// how many were written. Reaching the end of the input is not an error.
+func (r WrappedStdReader) WriteRecordsTo(w interface{ Write(record []string) error }) (n int, err error) {
+	return copyRecords(w, r.Reader)
+}
+
+// Dialect returns the dialect r reads. See csv.FromStdReaderConfig.
+func (r WrappedStdReader) Dialect() csv.Dialect {
+	d, _ := csv.FromStdReaderConfig(r.Reader)
+	return d
+}
+
+// A WrappedStdWriter wraps an encoding/csv Writer, adding DialectProvider.
+//
+// Can be created by calling WrapStdWriter.
+type WrappedStdWriter struct {
+	*stdcsv.Writer
+}
+
+// WrapStdWriter wraps w.
+func WrapStdWriter(w *stdcsv.Writer) WrappedStdWriter {
+	return WrappedStdWriter{w}
+}
+
+// Dialect returns the dialect w writes. See csv.FromStdWriterConfig.
+func (w WrappedStdWriter) Dialect() csv.Dialect {
+	return csv.FromStdWriterConfig(w.Writer)
+}
@@ -18,6 +18,8 @@ func TestWriterInterface(t *testing.T) {
 	iface = csv.NewWriter(new(bytes.Buffer))
 	iface = csv.NewDialectWriter(new(bytes.Buffer), csv.Dialect{})
 	iface = csv.NewWriter(new(bytes.Buffer))
+	iface = csv.NewFixedWidthWriter(new(bytes.Buffer), nil)
+	iface = csv.NewStdWriter(new(bytes.Buffer))
 
 	// To get rid of compile-time warning that this variable is not used.
 	iface.Flush()
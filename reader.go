@@ -6,16 +6,59 @@ package csv
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"unicode/utf8"
 )
 
+// Errors wrapped by the ParseError returned from Reader.Read.
+var (
+	// One of the Reader's Max* limits was exceeded.
+	ErrLimitExceeded = errors.New("limit exceeded")
+	// A quoted field was not terminated, or was followed by something else than
+	// a delimiter or line terminator. Only reported when recovering from
+	// errors or reading like encoding/csv (see ReaderOptions); otherwise such
+	// fields are read as they always have been.
+	ErrQuote = errors.New("extraneous or missing quote in quoted field")
+	// Error recovery skipped more than Reader.MaxErrors records.
+	ErrTooManyErrors = errors.New("too many errors")
+	// A record had another number of fields than Reader.FieldsPerRecord.
+	ErrFieldCount = errors.New("wrong number of fields")
+)
+
+// FieldsPerRecordFirst can be used as Reader.FieldsPerRecord to require all
+// records to have as many fields as the first one.
+const FieldsPerRecordFirst = -1
+
+// A ParseError is returned for parsing errors. Line and column numbers are
+// 1-indexed. Lines are counted by '\n' characters, like encoding/csv does.
+type ParseError struct {
+	Line   int   // Line where the error occurred.
+	Column int   // Column (rune index) where the error occurred.
+	Err    error // The actual error.
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("csv: line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error, which makes ParseError work with
+// errors.Is and errors.As.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 // bufio that supports putting stuff back into it.
 
 type unReader struct {
 	r *bufio.Reader
 	b *bytes.Buffer
+	// The exact input bytes of the rune last returned by ReadRune. These differ
+	// from the rune's UTF-8 encoding if the input was malformed.
+	last []byte
 }
 
 func newUnreader(r io.Reader) *unReader {
@@ -25,17 +68,51 @@ func newUnreader(r io.Reader) *unReader {
 	}
 }
 
+// readSource reads a rune from the underlying reader and appends its exact
+// input bytes to p.
+func (u *unReader) readSource(p []byte) (rune, int, []byte, error) {
+	r, size, err := u.r.ReadRune()
+	if err != nil {
+		return r, size, p, err
+	}
+	if r == utf8.RuneError && size == 1 {
+		// Keep the malformed byte instead of its replacement character.
+		u.r.UnreadRune()
+		c, _ := u.r.ReadByte()
+		return r, size, append(p, c), nil
+	}
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return r, size, append(p, buf[:n]...), nil
+}
+
 func (u *unReader) ReadRune() (rune, int, error) {
 	if u.b.Len() > 0 {
-		return u.b.ReadRune()
+		p := u.b.Bytes()
+		r, size, err := u.b.ReadRune()
+		u.last = append(u.last[:0], p[:size]...)
+		return r, size, err
 	}
-	return u.r.ReadRune()
+	r, size, last, err := u.readSource(u.last[:0])
+	u.last = last
+	return r, size, err
 }
 
 func (u *unReader) UnreadRune(r rune) {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	u.unread(buf[:n])
+}
+
+// unreadLast puts back the exact bytes of the rune last returned by ReadRune.
+func (u *unReader) unreadLast() {
+	u.unread(u.last)
+}
+
+func (u *unReader) unread(p []byte) {
 	// Poor man's prepend
 	var tmpBuf bytes.Buffer
-	tmpBuf.WriteRune(r)
+	tmpBuf.Write(p)
 	tmpBuf.ReadFrom(u.b)
 
 	u.b = &tmpBuf
@@ -44,11 +121,12 @@ func (u *unReader) UnreadRune(r rune) {
 func (u *unReader) NextIsString(s string) (bool, error) {
 	// Fill up bytes in buffer
 	for u.b.Len() < len(s) {
-		r, _, err := u.r.ReadRune()
+		var buf [utf8.UTFMax]byte
+		_, _, p, err := u.readSource(buf[:0])
 		if err != nil {
 			return false, err
 		}
-		u.b.WriteRune(r)
+		u.b.Write(p)
 	}
 	return strings.HasPrefix(u.b.String(), s), nil
 }
@@ -56,9 +134,94 @@ func (u *unReader) NextIsString(s string) (bool, error) {
 // A Reader reads records from a CSV-encoded file.
 //
 // Can be created by calling either NewReader or using NewDialectReader.
+//
+// The Max* fields can be set after construction to bound the resources used
+// when parsing untrusted input. Zero means no limit. Exceeding a limit makes
+// Read return a *ParseError wrapping ErrLimitExceeded.
 type Reader struct {
+	// MaxFieldBytes is the maximum number of bytes a single (unescaped) field
+	// may contain.
+	MaxFieldBytes int
+	// MaxRecordBytes is the maximum number of bytes a single record may span in
+	// the input, including quotes, delimiters and the line terminator.
+	MaxRecordBytes int
+	// MaxFieldsPerRecord is the maximum number of fields a single record may
+	// contain.
+	MaxFieldsPerRecord int
+	// MaxRecords is the maximum number of records that can be read, not
+	// counting the header.
+	MaxRecords int
+
+	// Setting OnError or Quarantine turns on error recovery. Instead of
+	// returning a *ParseError, Read then skips to the next line terminator and
+	// continues with the next record. The exact input bytes of the skipped
+	// record, truncated to MaxRecordBytes if set, are passed to OnError along
+	// with the error and written to Quarantine. Errors from the underlying
+	// io.Reader and MaxRecords are never recovered from.
+	OnError    func(raw []byte, err *ParseError)
+	Quarantine io.Writer
+	// MaxErrors is the number of records error recovery may skip before Read
+	// gives up and returns a *ParseError wrapping ErrTooManyErrors. Zero means
+	// no limit.
+	MaxErrors int
+
+	// FieldsPerRecord is the number of fields each record must have. Read
+	// returns the record along with a *ParseError wrapping ErrFieldCount if it
+	// has another number of fields. Zero means no check. FieldsPerRecordFirst
+	// sets it to the number of fields of the first record. Unlike for
+	// encoding/csv, zero is the default since this Reader has never checked.
+	FieldsPerRecord int
+	// ReuseRecord makes Read return a slice sharing the backing array of the
+	// one returned by the previous call, for performance.
+	ReuseRecord bool
+	// LazyQuotes makes a closing quote not followed by a delimiter or line
+	// terminator part of the field, and a quoted field left open at the end of
+	// the input end there, rather than being a *ParseError wrapping ErrQuote
+	// when recovering from errors.
+	LazyQuotes bool
+
 	opts Dialect
 	r    *unReader
+
+	// Read like encoding/csv: report malformed quoted fields even when not
+	// recovering, and skip empty lines. Set by ReaderOptions.Apply.
+	std bool
+
+	// Position of the last rune read. line is the number of '\n' read so far
+	// and column the number of runes read on the current line.
+	line, column int
+	// Number of input bytes read.
+	offset int64
+	// Start of each field of the last record read. See FieldPos.
+	fieldPos []position
+	// Undo information for the last rune read. Needed by unreadRune.
+	prevColumn, prevSize, prevRaw int
+
+	// Index into universalLineTerminators of the terminator last found by
+	// nextIsLineTerminator, and how many records each one has terminated.
+	// Only used with LineTerminatorAny.
+	universal       int
+	terminatorCount [len(universalLineTerminators)]int
+
+	recordBytes int          // Input bytes read for the current record.
+	records     int          // Number of records successfully read.
+	skipped     int          // Number of records skipped by error recovery.
+	terminated  bool         // Whether the current record's terminator was read.
+	keepRaw     bool         // Whether to capture raw input in raw.
+	raw         bytes.Buffer // Raw input of the current record.
+
+	// Header record and the error reading it. Only used with Dialect.Header.
+	header     []string
+	headerErr  error
+	headerRead bool
+
+	// The record last returned. Only used with ReuseRecord.
+	lastRecord []string
+}
+
+// A position is a 1-indexed line and column, as returned by FieldPos.
+type position struct {
+	line, column int
 }
 
 // Creates a reader that conforms to RFC 4180 and behaves identical as a
@@ -76,7 +239,7 @@ func NewDialectReader(r io.Reader, opts Dialect) *Reader {
 	opts.setDefaults()
 	return &Reader{
 		opts: opts,
-		r:    newUnreader(r),
+		r:    newUnreader(newDecoder(r, opts.Encoding)),
 	}
 }
 
@@ -104,13 +267,235 @@ func (r *Reader) ReadAll() ([][]string, error) {
 // Read reads one record from r. The record is a slice of strings with each
 // string representing one field.
 func (r *Reader) Read() ([]string, error) {
+	return r.read(false)
+}
+
+// ReadContext is like Read, but returns ctx.Err() instead of reading if ctx
+// is done. A Read blocked on the underlying io.Reader is not interrupted.
+func (r *Reader) ReadContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.Read()
+}
+
+// WriteRecordsTo writes the remaining records read from r to w, such as a
+// Writer, and returns how many were written. Reaching the end of the input
+// is not an error.
+func (r *Reader) WriteRecordsTo(w interface{ Write(record []string) error }) (n int, err error) {
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+		if err := w.Write(record); err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// FieldPos returns the line and column where the field with the given index
+// in the record most recently returned by Read starts. Both are 1-indexed.
+// Like for ParseError, lines are counted by '\n' characters and columns in
+// runes. Panics if the index is out of range, like encoding/csv does.
+func (r *Reader) FieldPos(field int) (line, column int) {
+	if field < 0 || field >= len(r.fieldPos) {
+		panic("out of range index passed to FieldPos")
+	}
+	p := r.fieldPos[field]
+	return p.line, p.column
+}
+
+// InputOffset returns the number of input bytes read so far, which is where
+// the record most recently returned by Read ends. If Dialect.Encoding is set,
+// bytes are counted after converting the input to UTF-8.
+func (r *Reader) InputOffset() int64 {
+	return r.offset
+}
+
+// Dialect returns the dialect of r, with defaults applied.
+func (r *Reader) Dialect() Dialect {
+	return r.opts
+}
+
+// ReadRaw is like Read, but also returns the exact input bytes the record was
+// parsed from, including original quoting, escape sequences and the line
+// terminator. If Dialect.Encoding is set, raw is converted to UTF-8.
+func (r *Reader) ReadRaw() (fields []string, raw []byte, err error) {
+	fields, err = r.read(true)
+	return fields, append([]byte(nil), r.raw.Bytes()...), err
+}
+
+// Header returns the header record if Dialect.Header is set, reading it
+// first if no record has been read yet. Returns nil if Dialect.Header is not
+// set.
+func (r *Reader) Header() ([]string, error) {
+	if !r.opts.Header {
+		return nil, nil
+	}
+	if !r.headerRead {
+		r.headerRead = true
+		r.header, r.headerErr = r.read(false)
+		if r.headerErr == nil {
+			// The header doesn't count towards MaxRecords.
+			r.records--
+		}
+		// Not to be overwritten by the next record.
+		r.lastRecord = nil
+	}
+	return r.header, r.headerErr
+}
+
+func (r *Reader) read(keepRaw bool) ([]string, error) {
+	if r.opts.Header && !r.headerRead {
+		if _, err := r.Header(); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if r.MaxRecords > 0 && r.records >= r.MaxRecords {
+			// Only an error if there actually is another record to read.
+			if _, err := r.r.NextIsString(" "); err != nil {
+				return nil, err
+			}
+			return nil, r.limitError("MaxRecords", r.MaxRecords)
+		}
+
+		r.recordBytes = 0
+		r.terminated = false
+		r.keepRaw = keepRaw || r.recovering()
+		r.raw.Reset()
+
+		if r.opts.Comment != 0 {
+			if ok, _ := r.r.NextIsString(string(r.opts.Comment)); ok {
+				if err := r.skipRecord(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if r.std {
+			if ok, _ := r.nextIsLineTerminator(); ok {
+				if err := r.skipRecord(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		line := r.line
+		record, err := r.readRecord()
+		if err == io.EOF && r.recordBytes > 0 {
+			// Last record lacked a line terminator. EOF is returned on the next
+			// call.
+			err = nil
+		}
+		if err == nil {
+			err = r.checkFieldCount(record, line)
+		}
+		if err == nil {
+			r.records++
+			if r.ReuseRecord {
+				r.lastRecord = record
+			}
+			return record, nil
+		}
+		perr, ok := err.(*ParseError)
+		if !ok || !r.recovering() {
+			return record, err
+		}
+		if err := r.recover(perr); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Skipped returns the number of bad records error recovery has skipped so far.
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+func (r *Reader) recovering() bool {
+	return r.OnError != nil || r.Quarantine != nil
+}
+
+// strictQuotes tells whether malformed quoted fields are reported as
+// ErrQuote. They are only when recovering or reading like encoding/csv, to
+// keep the behaviour of existing readers.
+func (r *Reader) strictQuotes() bool {
+	return r.std || r.recovering()
+}
+
+// recover skips the rest of the record that caused perr and reports it.
+func (r *Reader) recover(perr *ParseError) error {
+	if !r.terminated {
+		if err := r.skipRecord(); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	r.skipped++
+	if r.MaxErrors > 0 && r.skipped > r.MaxErrors {
+		return &ParseError{Line: perr.Line, Column: perr.Column, Err: ErrTooManyErrors}
+	}
+
+	raw := append([]byte(nil), r.raw.Bytes()...)
+	if r.OnError != nil {
+		r.OnError(raw, perr)
+	}
+	if r.Quarantine != nil {
+		if _, err := r.Quarantine.Write(raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipRecord discards input up to and including the next line terminator.
+// Limits are not enforced since nothing but raw input is kept.
+func (r *Reader) skipRecord() error {
+	for {
+		if ok, _ := r.nextIsLineTerminator(); ok {
+			for _ = range r.lineTerminator() {
+				if _, err := r.next(); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if _, err := r.next(); err != nil {
+			return err
+		}
+	}
+}
+
+// checkFieldCount enforces FieldsPerRecord on a record starting at line.
+func (r *Reader) checkFieldCount(record []string, line int) error {
+	switch {
+	case r.FieldsPerRecord == FieldsPerRecordFirst:
+		r.FieldsPerRecord = len(record)
+	case r.FieldsPerRecord > 0 && len(record) != r.FieldsPerRecord:
+		return &ParseError{Line: line + 1, Column: 1, Err: ErrFieldCount}
+	}
+	return nil
+}
+
+func (r *Reader) readRecord() ([]string, error) {
 	// TODO: Possible optimization; store the maximum number of columns for
 	// faster preallocation.
 	record := make([]string, 0, 2)
+	if r.ReuseRecord && r.lastRecord != nil {
+		record = r.lastRecord[:0]
+	}
 
 	firstPass := true
+	r.fieldPos = r.fieldPos[:0]
 
 	for {
+		r.fieldPos = append(r.fieldPos, position{line: r.line + 1, column: r.column + 1})
 		field, err := r.readField()
 		if firstPass {
 			firstPass = false
@@ -120,6 +505,9 @@ func (r *Reader) Read() ([]string, error) {
 
 		}
 		record = append(record, field)
+		if err == nil && r.MaxFieldsPerRecord > 0 && len(record) > r.MaxFieldsPerRecord {
+			err = r.limitError("MaxFieldsPerRecord", r.MaxFieldsPerRecord)
+		}
 		if err != nil {
 			return record, err
 		}
@@ -134,9 +522,16 @@ func (r *Reader) Read() ([]string, error) {
 		nextIsDelimiter, err := r.nextIsDelimiter()
 		if !nextIsDelimiter {
 			// Herein lies the devil!
+			if err == nil && r.strictQuotes() {
+				// Something else follows a quoted field.
+				err = r.parseError(ErrQuote)
+			}
 			return record, err
 		} else {
 			r.skipDelimiter()
+			if r.opts.SkipInitialSpace {
+				r.skipSpaces()
+			}
 		}
 
 	}
@@ -145,73 +540,221 @@ func (r *Reader) Read() ([]string, error) {
 	return record, nil
 }
 
+// next reads the next rune, keeping track of the current position and raw
+// input.
+func (r *Reader) next() (rune, error) {
+	char, size, err := r.r.ReadRune()
+	if err != nil {
+		return char, err
+	}
+	r.prevColumn, r.prevSize, r.prevRaw = r.column, size, 0
+	r.offset += int64(size)
+	if char == '\n' {
+		r.line++
+		r.column = 0
+	} else {
+		r.column++
+	}
+	r.recordBytes += size
+	if r.keepRaw && (r.MaxRecordBytes == 0 || r.raw.Len() < r.MaxRecordBytes) {
+		r.prevRaw, _ = r.raw.Write(r.r.last)
+	}
+	return char, nil
+}
+
+// readRune is like next, but also enforces MaxRecordBytes.
+func (r *Reader) readRune() (rune, error) {
+	char, err := r.next()
+	if err != nil {
+		return char, err
+	}
+	if r.MaxRecordBytes > 0 && r.recordBytes > r.MaxRecordBytes {
+		return char, r.limitError("MaxRecordBytes", r.MaxRecordBytes)
+	}
+	return char, nil
+}
+
+// unreadRune puts back the rune last returned by readRune.
+func (r *Reader) unreadRune(char rune) {
+	r.r.unreadLast()
+	if char == '\n' {
+		r.line--
+	}
+	r.column = r.prevColumn
+	r.offset -= int64(r.prevSize)
+	r.recordBytes -= r.prevSize
+	r.raw.Truncate(r.raw.Len() - r.prevRaw)
+}
+
+func (r *Reader) limitError(limit string, max int) error {
+	return r.parseError(fmt.Errorf("%w: %s is %d", ErrLimitExceeded, limit, max))
+}
+
+func (r *Reader) parseError(err error) error {
+	column := r.column
+	if column < 1 {
+		column = 1
+	}
+	return &ParseError{
+		Line:   r.line + 1,
+		Column: column,
+		Err:    err,
+	}
+}
+
+func (r *Reader) checkFieldBytes(s *bytes.Buffer) error {
+	if r.MaxFieldBytes > 0 && s.Len() > r.MaxFieldBytes {
+		return r.limitError("MaxFieldBytes", r.MaxFieldBytes)
+	}
+	return nil
+}
+
 func (r *Reader) readField() (string, error) {
-	char, _, err := r.r.ReadRune()
+	char, err := r.readRune()
 	if err != nil {
 		return "", err
 	}
 
 	// Let the next individual reader functions handle this.
-	r.r.UnreadRune(char)
+	r.unreadRune(char)
 
-	if char == r.opts.QuoteChar {
-		return r.readQuotedField()
+	if r.opts.QuoteString != "" {
+		if ok, _ := r.r.NextIsString(r.opts.QuoteString); ok {
+			return r.readQuotedField()
+		}
 	}
 	return r.readUnquotedField()
 }
 
+// atFieldEnd tells whether a delimiter, a line terminator or the end of the
+// input follows.
+func (r *Reader) atFieldEnd() bool {
+	if _, err := r.r.NextIsString(" "); err != nil {
+		return true
+	}
+	if ok, _ := r.nextIsDelimiter(); ok {
+		return true
+	}
+	ok, _ := r.nextIsLineTerminator()
+	return ok
+}
+
 func (r *Reader) nextIsLineTerminator() (bool, error) {
-	return r.r.NextIsString(r.opts.LineTerminator)
+	if r.opts.LineTerminator != LineTerminatorAny {
+		return r.r.NextIsString(r.opts.LineTerminator)
+	}
+	var err error
+	for i, lt := range universalLineTerminators {
+		var ok bool
+		if ok, err = r.r.NextIsString(lt); ok {
+			r.universal = i
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+// lineTerminator returns the line terminator last found by
+// nextIsLineTerminator.
+func (r *Reader) lineTerminator() string {
+	if r.opts.LineTerminator != LineTerminatorAny {
+		return r.opts.LineTerminator
+	}
+	return universalLineTerminators[r.universal]
+}
+
+// LineTerminator returns the line terminator used by the records read so far.
+// With LineTerminatorAny, this is the most common one, or
+// DefaultLineTerminator if no record has been terminated yet.
+func (r *Reader) LineTerminator() string {
+	if r.opts.LineTerminator != LineTerminatorAny {
+		return r.opts.LineTerminator
+	}
+	lt, max := DefaultLineTerminator, 0
+	for i, count := range r.terminatorCount {
+		if count > max {
+			lt, max = universalLineTerminators[i], count
+		}
+	}
+	return lt
 }
 
 func (r *Reader) nextIsDelimiter() (bool, error) {
-	return r.r.NextIsString(string(r.opts.Delimiter))
+	return r.r.NextIsString(r.opts.DelimiterString)
 }
 
 func (r *Reader) skipLineTerminator() error {
-	for _ = range r.opts.LineTerminator {
-		_, _, err := r.r.ReadRune()
+	for _ = range r.lineTerminator() {
+		_, err := r.next()
 		if err != nil {
 			return err
 		}
 	}
+	r.terminated = true
+	if r.opts.LineTerminator == LineTerminatorAny {
+		r.terminatorCount[r.universal]++
+	}
+	if r.MaxRecordBytes > 0 && r.recordBytes > r.MaxRecordBytes {
+		return r.limitError("MaxRecordBytes", r.MaxRecordBytes)
+	}
 	return nil
 }
 
 func (r *Reader) skipDelimiter() error {
-	_, _, err := r.r.ReadRune()
-	return err
+	return r.skipString(r.opts.DelimiterString)
+}
+
+func (r *Reader) skipSpaces() error {
+	for {
+		if ok, err := r.r.NextIsString(" "); !ok {
+			return err
+		}
+		if _, err := r.readRune(); err != nil {
+			return err
+		}
+	}
+}
+
+// skipString skips as many runes as there are in s. Make sure s is next before
+// calling this.
+func (r *Reader) skipString(s string) error {
+	for _ = range s {
+		if _, err := r.readRune(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *Reader) readQuotedField() (string, error) {
-	char, _, err := r.r.ReadRune()
-	if err != nil {
-		return "", err
+	if ok, _ := r.r.NextIsString(r.opts.QuoteString); !ok {
+		panic("Expected field to start with quote string.")
 	}
-	if char != r.opts.QuoteChar {
-		panic("Expected first character to be quote character.")
+	if err := r.skipString(r.opts.QuoteString); err != nil {
+		return "", err
 	}
 
 	s := bytes.Buffer{}
 	for {
-		char, _, err := r.r.ReadRune()
-		if err != nil {
-			return s.String(), err
-		}
-		if char != r.opts.QuoteChar {
-			s.WriteRune(char)
-		} else {
+		if ok, _ := r.r.NextIsString(r.opts.CloseQuoteString); ok {
+			if err := r.skipString(r.opts.CloseQuoteString); err != nil {
+				return s.String(), err
+			}
 			switch r.opts.DoubleQuote {
 			case DoDoubleQuote:
-				char, _, err = r.r.ReadRune()
-				if err != nil {
+				if ok, _ := r.r.NextIsString(r.opts.CloseQuoteString); !ok {
+					if r.LazyQuotes && !r.atFieldEnd() {
+						s.WriteString(r.opts.CloseQuoteString)
+						continue
+					}
+					return s.String(), nil
+				}
+				if err := r.skipString(r.opts.CloseQuoteString); err != nil {
 					return s.String(), err
 				}
-				if char == r.opts.QuoteChar {
-					s.WriteRune(char)
-				} else {
-					r.r.UnreadRune(char)
-					return s.String(), nil
+				s.WriteString(r.opts.CloseQuoteString)
+				if err := r.checkFieldBytes(&s); err != nil {
+					return s.String(), err
 				}
 			case NoDoubleQuote:
 				if s.Len() == 0 {
@@ -223,14 +766,32 @@ func (r *Reader) readQuotedField() (string, error) {
 				}
 				if lastRune == r.opts.EscapeChar {
 					// Replace previous escape character.
-					s.Truncate(s.Len() - utf8.RuneLen(char))
-					s.WriteRune(char)
+					s.Truncate(s.Len() - size)
+					s.WriteString(r.opts.CloseQuoteString)
+				} else if r.LazyQuotes && !r.atFieldEnd() {
+					s.WriteString(r.opts.CloseQuoteString)
 				} else {
 					return s.String(), nil
 				}
 			default:
 				panic("Unrecognized double quote mode.")
 			}
+			continue
+		}
+
+		char, err := r.readRune()
+		if err == io.EOF {
+			if r.LazyQuotes || !r.strictQuotes() {
+				return s.String(), err
+			}
+			return s.String(), r.parseError(ErrQuote)
+		}
+		if err != nil {
+			return s.String(), err
+		}
+		s.WriteRune(char)
+		if err := r.checkFieldBytes(&s); err != nil {
+			return s.String(), err
 		}
 	}
 
@@ -242,23 +803,27 @@ func (r *Reader) readUnquotedField() (string, error) {
 	// TODO: Use bytes.Buffer
 	s := bytes.Buffer{}
 	for {
-		char, _, err := r.r.ReadRune()
-		if err != nil || char == r.opts.Delimiter {
-			// TODO Can a non quoted string be escaped? In that case, it should be
-			// handled here. Should probably have a look at how Python's csv module
-			// is handling this.
-
-			// Putting it back for the outer loop to read separators. This makes more
-			// compatible with readQuotedField().
-			r.r.UnreadRune(char)
+		// TODO Can a non quoted string be escaped? In that case, it should be
+		// handled here. Should probably have a look at how Python's csv module
+		// is handling this.
 
-			return s.String(), err
-		} else {
-			s.WriteRune(char)
+		// Leaving delimiters and line terminators for the outer loop to read.
+		// This makes more compatible with readQuotedField().
+		if ok, _ := r.nextIsDelimiter(); ok {
+			return s.String(), nil
 		}
 		if ok, _ := r.nextIsLineTerminator(); ok {
 			return s.String(), nil
 		}
+
+		char, err := r.readRune()
+		if err != nil {
+			return s.String(), err
+		}
+		s.WriteRune(char)
+		if err := r.checkFieldBytes(&s); err != nil {
+			return s.String(), err
+		}
 	}
 
 	// Required by Go 1.0 to compile. Unreachable code.
@@ -6,6 +6,7 @@ package csv
 import (
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"io"
 	"reflect"
 	"strings"
@@ -148,7 +149,369 @@ func TestReadAll(t *testing.T) {
 	}
 }
 
-func testReaderQuick(t *testing.T, quoting int) {
+func TestReaderLimits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		setup  func(r *Reader)
+		line   int
+		column int
+	}{
+		{
+			"MaxFieldBytes unquoted",
+			"a bcdef\n",
+			func(r *Reader) { r.MaxFieldBytes = 3 },
+			1, 6,
+		},
+		{
+			"MaxFieldBytes runaway quote",
+			"a \"b c\nd e f\ng h i\n",
+			func(r *Reader) { r.MaxFieldBytes = 8 },
+			2, 5,
+		},
+		{
+			"MaxRecordBytes",
+			"a b\nc d e f g\n",
+			func(r *Reader) { r.MaxRecordBytes = 6 },
+			2, 7,
+		},
+		{
+			"MaxFieldsPerRecord",
+			"a b\nc d e\n",
+			func(r *Reader) { r.MaxFieldsPerRecord = 2 },
+			2, 5,
+		},
+		{
+			"MaxRecords",
+			"a\nb\nc\n",
+			func(r *Reader) { r.MaxRecords = 2 },
+			3, 1,
+		},
+	}
+
+	for _, test := range tests {
+		r := NewReader(strings.NewReader(test.input))
+		test.setup(r)
+		_, err := r.ReadAll()
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Error(test.name, "- unexpected error:", err)
+			continue
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Error(test.name, "- expected a *ParseError, got:", err)
+			continue
+		}
+		if perr.Line != test.line || perr.Column != test.column {
+			t.Error(test.name, "- unexpected position:", perr.Line, perr.Column, "Expected:", test.line, test.column)
+		}
+	}
+}
+
+func TestReaderLimitsNotExceeded(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(strings.NewReader("a \"b c\" d\ne f g\n"))
+	r.MaxFieldBytes = 3
+	r.MaxRecordBytes = 10
+	r.MaxFieldsPerRecord = 3
+	r.MaxRecords = 2
+
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"a", "b c", "d"}, {"e", "f", "g"}}) {
+		t.Error("Unexpected output:", data)
+	}
+}
+
+func TestReaderMaxRecordsWithHeader(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("h\na\nb\n"), Dialect{Header: true})
+	r.MaxRecords = 2
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"a"}, {"b"}}) {
+		t.Error("Unexpected output:", data)
+	}
+
+	r = NewDialectReader(strings.NewReader("h\na\nb\nc\n"), Dialect{Header: true})
+	r.MaxRecords = 2
+	var perr *ParseError
+	if _, err := r.ReadAll(); !errors.Is(err, ErrLimitExceeded) || !errors.As(err, &perr) || perr.Line != 4 {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestReaderErrorRecovery(t *testing.T) {
+	t.Parallel()
+
+	var raws []string
+	var errs []*ParseError
+	r := NewReader(strings.NewReader("a b\n\"c\"x d\n\"\xff\"\xfe\ne f\n"))
+	r.OnError = func(raw []byte, err *ParseError) {
+		raws = append(raws, string(raw))
+		errs = append(errs, err)
+	}
+
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"a", "b"}, {"e", "f"}}) {
+		t.Error("Unexpected output:", data)
+	}
+	if !reflect.DeepEqual(raws, []string{"\"c\"x d\n", "\"\xff\"\xfe\n"}) {
+		t.Errorf("Unexpected raw records: %q", raws)
+	}
+	if r.Skipped() != 2 {
+		t.Error("Unexpected number of skipped records:", r.Skipped())
+	}
+	for i, err := range errs {
+		if !errors.Is(err, ErrQuote) || err.Line != i+2 {
+			t.Error("Unexpected error:", err)
+		}
+	}
+}
+
+func TestReaderMalformedQuotesWithoutRecovery(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(strings.NewReader("\"c\"x d\n\"e f"))
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"c"}, {"x", "d"}, {"e f"}}) {
+		t.Errorf("Unexpected output: %q", data)
+	}
+}
+
+func TestReaderErrorRecoveryResynchronises(t *testing.T) {
+	t.Parallel()
+
+	quarantine := new(bytes.Buffer)
+	r := NewReader(strings.NewReader("a \"b c\nd e\nf g\n"))
+	r.MaxFieldBytes = 4
+	r.Quarantine = quarantine
+
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"f", "g"}}) {
+		t.Error("Unexpected output:", data)
+	}
+	if s := quarantine.String(); s != "a \"b c\nd e\n" {
+		t.Errorf("Unexpected quarantine: %q", s)
+	}
+}
+
+func TestReaderMaxErrors(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(strings.NewReader("\"a\"b\n\"c\"d\ne f\n"))
+	r.Quarantine = new(bytes.Buffer)
+	r.MaxErrors = 1
+
+	_, err := r.ReadAll()
+	if !errors.Is(err, ErrTooManyErrors) {
+		t.Error("Unexpected error:", err)
+	}
+	if r.Skipped() != 2 {
+		t.Error("Unexpected number of skipped records:", r.Skipped())
+	}
+}
+
+func TestReadRaw(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("a,\"b\"\"c\"\r\nd,e\r\n"), Dialect{
+		Delimiter:      ',',
+		LineTerminator: "\r\n",
+	})
+	fields, raw, err := r.ReadRaw()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(fields, []string{"a", "b\"c"}) {
+		t.Error("Unexpected fields:", fields)
+	}
+	if s := string(raw); s != "a,\"b\"\"c\"\r\n" {
+		t.Errorf("Unexpected raw record: %q", s)
+	}
+
+	r = NewDialectReader(strings.NewReader("\"d\\\"\",e\r\n"), Dialect{
+		Delimiter:      ',',
+		LineTerminator: "\r\n",
+		DoubleQuote:    NoDoubleQuote,
+	})
+	fields, raw, err = r.ReadRaw()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(fields, []string{"d\"", "e"}) {
+		t.Error("Unexpected fields:", fields)
+	}
+	if s := string(raw); s != "\"d\\\"\",e\r\n" {
+		t.Errorf("Unexpected raw record: %q", s)
+	}
+}
+
+func TestReadingMultiCharDelimiterAndQuotes(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("a||<<b||c>>||<<d>>>>e>>||\n"), Dialect{
+		DelimiterString:  "||",
+		QuoteString:      "<<",
+		CloseQuoteString: ">>",
+	})
+	err := testReadingSingleLine(t, r, []string{"a", "b||c", "d>>e", ""})
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestReadingEmptyLastField(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("a,\nb,c\n"), Dialect{Delimiter: ','})
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"a", ""}, {"b", "c"}}) {
+		t.Error("Unexpected output:", data)
+	}
+}
+
+func TestReadingUniversalNewlines(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("a b\r\nc \"d\re\"\nf g\rh i\r\n"), Dialect{
+		LineTerminator: LineTerminatorAny,
+	})
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	expected := [][]string{{"a", "b"}, {"c", "d\re"}, {"f", "g"}, {"h", "i"}}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Unexpected output: %q", data)
+	}
+	if lt := r.LineTerminator(); lt != "\r\n" {
+		t.Errorf("Unexpected line terminator: %q", lt)
+	}
+
+	b := new(bytes.Buffer)
+	w := NewDialectWriter(b, Dialect{LineTerminator: LineTerminatorAny})
+	w.WriteAll(expected)
+	r = NewDialectReader(b, Dialect{LineTerminator: LineTerminatorAny})
+	if data, _ := r.ReadAll(); !reflect.DeepEqual(data, expected) {
+		t.Errorf("Unexpected round trip output: %q", data)
+	}
+	if lt := r.LineTerminator(); lt != DefaultLineTerminator {
+		t.Errorf("Unexpected line terminator: %q", lt)
+	}
+}
+
+func TestReadingSkipInitialSpace(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("a,  b, \"c\"\n"), Dialect{
+		Delimiter:        ',',
+		SkipInitialSpace: true,
+	})
+	err := testReadingSingleLine(t, r, []string{"a", "b", "c"})
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestReadingUnterminatedLastRecord(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("a,b\nc,\"d\""), Dialect{Delimiter: ','})
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"a", "b"}, {"c", "d"}}) {
+		t.Error("Unexpected output:", data)
+	}
+}
+
+func TestReadingEncodings(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		encoding string
+		input    string
+	}{
+		{EncodingUTF8, "\xef\xbb\xbfa,\xc3\xa9\xe2\x82\xac\n"},
+		{EncodingUTF16LE, "\xff\xfea\x00,\x00\xe9\x00\xac\x20\n\x00"},
+		{EncodingUTF16BE, "\x00a\x00,\x00\xe9\x20\xac\x00\n"},
+		{EncodingWindows1252, "a,\xe9\x80\n"},
+	}
+	for _, test := range tests {
+		r := NewDialectReader(strings.NewReader(test.input), Dialect{
+			Delimiter: ',',
+			Encoding:  test.encoding,
+		})
+		err := testReadingSingleLine(t, r, []string{"a", "é€"})
+		if err != nil {
+			t.Error("Unexpected error:", test.encoding, err)
+		}
+	}
+
+	r := NewDialectReader(strings.NewReader("a\n"), Dialect{Encoding: "ebcdic"})
+	if _, err := r.Read(); !errors.Is(err, ErrUnknownEncoding) {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestReadingCommentsAndHeader(t *testing.T) {
+	t.Parallel()
+
+	input := "# generated\nid,name\n1,a\n# note\n2,b\n#"
+	r := NewDialectReader(strings.NewReader(input), Dialect{
+		Delimiter: ',',
+		Comment:   '#',
+		Header:    true,
+	})
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{"1", "a"}, {"2", "b"}}) {
+		t.Error("Unexpected output:", data)
+	}
+	if header, err := r.Header(); err != nil || !reflect.DeepEqual(header, []string{"id", "name"}) {
+		t.Error("Unexpected header:", header, err)
+	}
+
+	// Header reads the header before any record is read.
+	r = NewDialectReader(strings.NewReader("id\n1\n"), Dialect{Header: true})
+	if header, _ := r.Header(); !reflect.DeepEqual(header, []string{"id"}) {
+		t.Error("Unexpected header:", header)
+	}
+	if record, _ := r.Read(); !reflect.DeepEqual(record, []string{"1"}) {
+		t.Error("Unexpected record:", record)
+	}
+
+	r = NewReader(strings.NewReader("id\n"))
+	if header, err := r.Header(); header != nil || err != nil {
+		t.Error("Unexpected header:", header, err)
+	}
+}
+
+func testReaderQuick(t *testing.T, quoting Quoting) {
 	f := func(records [][]string, doubleQuote bool, escapeChar, del, quoteChar rune, lt string) bool {
 		dialect := Dialect{
 			Quoting:        quoting,
@@ -184,6 +547,89 @@ func testReaderQuick(t *testing.T, quoting int) {
 	}
 }
 
+func TestReadingWithoutQuotes(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("\"a\tb\"\t'c\\\n"), Dialect{
+		Delimiter:  '\t',
+		QuoteChar:  NoChar,
+		EscapeChar: NoChar,
+	})
+	data, err := r.ReadAll()
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(data, [][]string{{`"a`, `b"`, `'c\`}}) {
+		t.Error("Unexpected output:", data)
+	}
+}
+
+func TestReaderFieldsPerRecord(t *testing.T) {
+	t.Parallel()
+
+	r := NewReader(strings.NewReader("a b\nc d e\nf g\n"))
+	r.FieldsPerRecord = FieldsPerRecordFirst
+	if _, err := r.Read(); err != nil || r.FieldsPerRecord != 2 {
+		t.Error("Unexpected result:", r.FieldsPerRecord, err)
+	}
+	record, err := r.Read()
+	if perr, ok := err.(*ParseError); !ok || !errors.Is(err, ErrFieldCount) || perr.Line != 2 {
+		t.Error("Unexpected error:", err)
+	}
+	if !reflect.DeepEqual(record, []string{"c", "d", "e"}) {
+		t.Error("Unexpected record:", record)
+	}
+	if record, err := r.Read(); err != nil || !reflect.DeepEqual(record, []string{"f", "g"}) {
+		t.Error("Unexpected record:", record, err)
+	}
+
+	// Zero means no check.
+	r = NewReader(strings.NewReader("a b\nc d e\n"))
+	if _, err := r.ReadAll(); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestReaderReuseRecord(t *testing.T) {
+	t.Parallel()
+
+	r := NewDialectReader(strings.NewReader("h\na b\nc d\n"), Dialect{Header: true})
+	r.ReuseRecord = true
+	first, _ := r.Read()
+	second, _ := r.Read()
+	if &first[0] != &second[0] || !reflect.DeepEqual(second, []string{"c", "d"}) {
+		t.Error("Unexpected records:", first, second)
+	}
+	if header, _ := r.Header(); !reflect.DeepEqual(header, []string{"h"}) {
+		t.Error("Unexpected header:", header)
+	}
+}
+
+func TestReaderLazyQuotes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][][]string{
+		`"a"b",c` + "\n": {{`a"b`, "c"}},
+		`"a""b" ,c`:      {{`a"b" ,c`}},
+		`"a,b`:           {{"a,b"}},
+	}
+	for input, expected := range tests {
+		r := NewDialectReader(strings.NewReader(input), Dialect{Delimiter: ','})
+		r.LazyQuotes = true
+		data, err := r.ReadAll()
+		if err != nil || !reflect.DeepEqual(data, expected) {
+			t.Errorf("Unexpected output for %q: %q, %v", input, data, err)
+		}
+
+		var perr *ParseError
+		r = NewDialectReader(strings.NewReader(input), Dialect{Delimiter: ','})
+		r.OnError = func(raw []byte, err *ParseError) { perr = err }
+		if _, err := r.ReadAll(); err != nil || !errors.Is(perr, ErrQuote) {
+			t.Errorf("Unexpected error for %q: %v, %v", input, err, perr)
+		}
+	}
+}
+
 // Test writing to and then reading from using various CSV dialects.
 func TestReaderQuick(t *testing.T) {
 	t.Parallel()
@@ -0,0 +1,76 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	dialectsMu sync.RWMutex
+	dialects   = map[string]Dialect{
+		// As written by Microsoft Excel, and described by RFC 4180.
+		"excel": {
+			Delimiter:      ',',
+			QuoteChar:      '"',
+			DoubleQuote:    DoDoubleQuote,
+			Quoting:        QuoteMinimal,
+			LineTerminator: "\r\n",
+		},
+		"excel-tab": {
+			Delimiter:      '\t',
+			QuoteChar:      '"',
+			DoubleQuote:    DoDoubleQuote,
+			Quoting:        QuoteMinimal,
+			LineTerminator: "\r\n",
+		},
+		"rfc4180": {
+			Delimiter:      ',',
+			QuoteChar:      '"',
+			DoubleQuote:    DoDoubleQuote,
+			Quoting:        QuoteMinimal,
+			LineTerminator: "\r\n",
+		},
+		// As usually generated on UNIX systems, quoting every field.
+		"unix": {
+			Delimiter:      ',',
+			QuoteChar:      '"',
+			DoubleQuote:    DoDoubleQuote,
+			Quoting:        QuoteAll,
+			LineTerminator: "\n",
+		},
+	}
+)
+
+// RegisterDialect makes a dialect available by name through LookupDialect.
+// Names are case insensitive. Registering an existing name replaces it.
+func RegisterDialect(name string, d Dialect) {
+	dialectsMu.Lock()
+	defer dialectsMu.Unlock()
+	dialects[strings.ToLower(name)] = d
+}
+
+// LookupDialect returns the dialect registered under name. Besides the
+// dialects registered using RegisterDialect, "excel", "excel-tab", "rfc4180"
+// and "unix" are available.
+func LookupDialect(name string) (Dialect, bool) {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	d, ok := dialects[strings.ToLower(name)]
+	return d, ok
+}
+
+// Dialects returns the sorted names of all registered dialects.
+func Dialects() []string {
+	dialectsMu.RLock()
+	defer dialectsMu.RUnlock()
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,39 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"errors"
+	"io"
+)
+
+// A Sniffer detects the dialect of r by inspecting its beginning. It returns a
+// reader that replays everything read from r.
+type Sniffer func(r io.Reader) (Dialect, io.Reader, error)
+
+// ErrNoSniffer is returned by NewSniffingReader if no Sniffer is registered.
+var ErrNoSniffer = errors.New("csv: no sniffer registered, import github.com/eltorocorp/go-csv/detector")
+
+var sniffer Sniffer
+
+// RegisterSniffer makes NewSniffingReader use s. The detector package
+// registers its sniffer when imported, so usually all that is needed is:
+//
+//	import _ "github.com/eltorocorp/go-csv/detector"
+func RegisterSniffer(s Sniffer) {
+	sniffer = s
+}
+
+// Creates a reader that configures itself using the dialect detected by the
+// registered Sniffer.
+func NewSniffingReader(r io.Reader) (*Reader, error) {
+	if sniffer == nil {
+		return nil, ErrNoSniffer
+	}
+	dialect, replay, err := sniffer(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewDialectReader(replay, dialect), nil
+}
@@ -0,0 +1,261 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"context"
+	stdcsv "encoding/csv"
+	"io"
+)
+
+// ReaderOptions are the settings of a Reader that aren't part of its Dialect.
+// See the Reader fields of the same names.
+type ReaderOptions struct {
+	FieldsPerRecord int
+	LazyQuotes      bool
+	ReuseRecord     bool
+}
+
+// Apply sets the options on r. Like encoding/csv, r then skips empty lines
+// and reports malformed quoted fields as ErrQuote unless LazyQuotes is set.
+func (o ReaderOptions) Apply(r *Reader) {
+	r.std = true
+	r.FieldsPerRecord = o.FieldsPerRecord
+	r.LazyQuotes = o.LazyQuotes
+	r.ReuseRecord = o.ReuseRecord
+}
+
+// FromStdReaderConfig maps the exported fields of an encoding/csv.Reader to
+// a Dialect and ReaderOptions. Used together, they read what the
+// encoding/csv.Reader would:
+//
+//	dialect, opts := csv.FromStdReaderConfig(std)
+//	r := csv.NewDialectReader(file, dialect)
+//	opts.Apply(r)
+//
+// Lines may be terminated by "\r\n", "\n" and also "\r". TrimLeadingSpace
+// only skips spaces following a delimiter, not tabs nor those starting a
+// line.
+func FromStdReaderConfig(std *stdcsv.Reader) (Dialect, ReaderOptions) {
+	d := Dialect{
+		Delimiter:        std.Comma,
+		QuoteChar:        '"',
+		EscapeChar:       NoChar,
+		DoubleQuote:      DoDoubleQuote,
+		Quoting:          QuoteMinimal,
+		LineTerminator:   LineTerminatorAny,
+		Comment:          std.Comment,
+		SkipInitialSpace: std.TrimLeadingSpace,
+	}
+	opts := ReaderOptions{
+		FieldsPerRecord: fromStdFieldsPerRecord(std.FieldsPerRecord),
+		LazyQuotes:      std.LazyQuotes,
+		ReuseRecord:     std.ReuseRecord,
+	}
+	return d, opts
+}
+
+// fromStdFieldsPerRecord maps encoding/csv.Reader.FieldsPerRecord to
+// Reader.FieldsPerRecord. The two use zero and negative numbers the other way
+// around.
+func fromStdFieldsPerRecord(n int) int {
+	switch {
+	case n == 0:
+		return FieldsPerRecordFirst
+	case n < 0:
+		return 0
+	}
+	return n
+}
+
+// FromStdWriterConfig maps the exported fields of an encoding/csv.Writer to
+// a Dialect writing what the encoding/csv.Writer would, except that fields
+// starting with a space aren't quoted. Neither are fields holding a lone "\r",
+// nor a lone "\n" if UseCRLF is set, unless written by a StdWriter.
+func FromStdWriterConfig(std *stdcsv.Writer) Dialect {
+	return stdWriterDialect(std.Comma, std.UseCRLF)
+}
+
+func stdWriterDialect(comma rune, useCRLF bool) Dialect {
+	d := Dialect{
+		Delimiter:      comma,
+		QuoteChar:      '"',
+		EscapeChar:     NoChar,
+		DoubleQuote:    DoDoubleQuote,
+		Quoting:        QuoteMinimal,
+		LineTerminator: "\n",
+	}
+	if useCRLF {
+		d.LineTerminator = "\r\n"
+	}
+	return d
+}
+
+// A StdReader is a Reader configured through the same exported fields as an
+// encoding/csv.Reader, which eases migrating from it. See
+// FromStdReaderConfig for how the fields are interpreted. They must be set
+// before the first call to Read.
+//
+// Can be created by calling NewStdReader.
+type StdReader struct {
+	Comma            rune
+	Comment          rune
+	FieldsPerRecord  int
+	LazyQuotes       bool
+	TrimLeadingSpace bool
+	ReuseRecord      bool
+
+	src io.Reader
+	r   *Reader
+}
+
+// NewStdReader creates a reader reading from r with the defaults of
+// encoding/csv.NewReader.
+func NewStdReader(r io.Reader) *StdReader {
+	return &StdReader{
+		Comma: ',',
+		src:   r,
+	}
+}
+
+// reader returns the Reader doing the reading, creating it on first use.
+func (r *StdReader) reader() *Reader {
+	if r.r == nil {
+		dialect, opts := FromStdReaderConfig(&stdcsv.Reader{
+			Comma:            r.Comma,
+			Comment:          r.Comment,
+			FieldsPerRecord:  r.FieldsPerRecord,
+			LazyQuotes:       r.LazyQuotes,
+			TrimLeadingSpace: r.TrimLeadingSpace,
+			ReuseRecord:      r.ReuseRecord,
+		})
+		r.r = NewDialectReader(r.src, dialect)
+		opts.Apply(r.r)
+	}
+	return r.r
+}
+
+// Read reads one record from r. The record is a slice of strings with each
+// string representing one field. Like for encoding/csv, FieldsPerRecord is
+// set to the number of fields of the first record if it is zero.
+func (r *StdReader) Read() ([]string, error) {
+	record, err := r.reader().Read()
+	if r.FieldsPerRecord == 0 && r.r.FieldsPerRecord > 0 {
+		r.FieldsPerRecord = r.r.FieldsPerRecord
+	}
+	return record, err
+}
+
+// ReadAll reads all the remaining records from r. Each record is a slice of
+// fields. A successful call returns err == nil, not err == EOF. Because
+// ReadAll is defined to read until EOF, it does not treat end of file as an
+// error to be reported.
+func (r *StdReader) ReadAll() ([][]string, error) {
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if r.ReuseRecord {
+			record = append([]string(nil), record...)
+		}
+		records = append(records, record)
+	}
+}
+
+// ReadContext is like Read, but returns ctx.Err() instead of reading if ctx
+// is done.
+func (r *StdReader) ReadContext(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.Read()
+}
+
+// WriteRecordsTo writes the remaining records read from r to w and returns
+// how many were written. See Reader.WriteRecordsTo.
+func (r *StdReader) WriteRecordsTo(w interface{ Write(record []string) error }) (int, error) {
+	return r.reader().WriteRecordsTo(w)
+}
+
+// FieldPos returns the line and column where the field with the given index
+// in the record most recently returned by Read starts. See Reader.FieldPos.
+func (r *StdReader) FieldPos(field int) (line, column int) {
+	return r.reader().FieldPos(field)
+}
+
+// InputOffset returns the number of input bytes read so far. See
+// Reader.InputOffset.
+func (r *StdReader) InputOffset() int64 {
+	return r.reader().InputOffset()
+}
+
+// Dialect returns the dialect r reads, with defaults applied.
+func (r *StdReader) Dialect() Dialect {
+	return r.reader().Dialect()
+}
+
+// A StdWriter is a Writer configured through the same exported fields as an
+// encoding/csv.Writer, which eases migrating from it. See FromStdWriterConfig
+// for how the fields are interpreted. They must be set before the first call
+// to Write.
+//
+// Can be created by calling NewStdWriter.
+type StdWriter struct {
+	Comma   rune
+	UseCRLF bool
+
+	dst io.Writer
+	w   *Writer
+}
+
+// NewStdWriter creates a writer writing to w with the defaults of
+// encoding/csv.NewWriter.
+func NewStdWriter(w io.Writer) *StdWriter {
+	return &StdWriter{
+		Comma: ',',
+		dst:   w,
+	}
+}
+
+// writer returns the Writer doing the writing, creating it on first use.
+func (w *StdWriter) writer() *Writer {
+	if w.w == nil {
+		writer := NewDialectWriter(w.dst, stdWriterDialect(w.Comma, w.UseCRLF))
+		writer.stdLineBreaks = true
+		w.w = &writer
+	}
+	return w.w
+}
+
+// Write writes a single CSV record to w along with any necessary quoting. A
+// record is a slice of strings with each string being one field.
+func (w *StdWriter) Write(record []string) error {
+	return w.writer().Write(record)
+}
+
+// WriteAll writes multiple CSV records to w using Write and then calls Flush.
+func (w *StdWriter) WriteAll(records [][]string) error {
+	return w.writer().WriteAll(records)
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+// To check if an error occurred during the Flush, call Error.
+func (w *StdWriter) Flush() {
+	w.writer().Flush()
+}
+
+// Error reports any error that has occurred during a previous Write or Flush.
+func (w *StdWriter) Error() error {
+	return w.writer().Error()
+}
+
+// Dialect returns the dialect w writes, with defaults applied.
+func (w *StdWriter) Dialect() Dialect {
+	return w.writer().Dialect()
+}
@@ -0,0 +1,116 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bytes"
+	stdcsv "encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStdReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input     string
+		configure func(r *stdcsv.Reader)
+	}{
+		{"a,b\r\n\"c\"\"d\",e\n", func(r *stdcsv.Reader) {}},
+		{"a;b\n# comment\nc; d\n", func(r *stdcsv.Reader) {
+			r.Comma = ';'
+			r.Comment = '#'
+			r.TrimLeadingSpace = true
+		}},
+		{"a,b\nc\n", func(r *stdcsv.Reader) { r.FieldsPerRecord = -1 }},
+		{"a,\"b\"c\",d\n", func(r *stdcsv.Reader) { r.LazyQuotes = true }},
+		{"a,b\n\nc,d\n", func(r *stdcsv.Reader) {}},
+		{"\r\na,b\r\n\r\n\r\nc,\"\n\nd\"\n\n", func(r *stdcsv.Reader) {}},
+	}
+	for _, test := range tests {
+		std := stdcsv.NewReader(strings.NewReader(test.input))
+		test.configure(std)
+		expected, err := std.ReadAll()
+		if err != nil {
+			t.Error("Unexpected error:", err)
+			continue
+		}
+
+		r := NewStdReader(strings.NewReader(test.input))
+		r.Comma, r.Comment = std.Comma, std.Comment
+		r.FieldsPerRecord, r.LazyQuotes = std.FieldsPerRecord, std.LazyQuotes
+		r.TrimLeadingSpace = std.TrimLeadingSpace
+		data, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", test.input, err)
+		}
+		if !reflect.DeepEqual(data, expected) {
+			t.Errorf("Unexpected output for %q: %q, expected %q", test.input, data, expected)
+		}
+	}
+
+	// A record with another number of fields than the first one is an error.
+	r := NewStdReader(strings.NewReader("a,b\nc\n"))
+	if _, err := r.ReadAll(); err == nil || r.FieldsPerRecord != 2 {
+		t.Error("Unexpected result:", r.FieldsPerRecord, err)
+	}
+
+	// So is a bare quote, unless LazyQuotes is set.
+	r = NewStdReader(strings.NewReader("a,\"b\"c\",d\n"))
+	if _, err := r.ReadAll(); !errors.Is(err, ErrQuote) {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestFromStdReaderConfig(t *testing.T) {
+	t.Parallel()
+
+	std := stdcsv.NewReader(nil)
+	std.Comma = '\t'
+	std.FieldsPerRecord = -1
+	std.ReuseRecord = true
+	dialect, opts := FromStdReaderConfig(std)
+	if dialect.Delimiter != '\t' || dialect.EscapeChar != NoChar || dialect.Validate() != nil {
+		t.Errorf("Unexpected dialect: %#v", dialect)
+	}
+	if opts != (ReaderOptions{ReuseRecord: true}) {
+		t.Errorf("Unexpected options: %#v", opts)
+	}
+
+	r := NewDialectReader(strings.NewReader("a\tb\n"), dialect)
+	opts.Apply(r)
+	if !r.ReuseRecord || r.FieldsPerRecord != 0 {
+		t.Error("Options not applied")
+	}
+}
+
+func TestStdWriter(t *testing.T) {
+	t.Parallel()
+
+	for _, useCRLF := range []bool{false, true} {
+		records := [][]string{{"a", "b,c", `d"e`}, {"", "f"}, {"h\ni", "x"}, {"j\r\nk", "l\rm"}}
+		expected := new(bytes.Buffer)
+		std := stdcsv.NewWriter(expected)
+		std.Comma = ';'
+		std.UseCRLF = useCRLF
+		std.WriteAll(records)
+
+		b := new(bytes.Buffer)
+		w := NewStdWriter(b)
+		w.Comma = ';'
+		w.UseCRLF = useCRLF
+		if err := w.WriteAll(records); err != nil {
+			t.Error("Unexpected error:", err)
+		}
+		if b.String() != expected.String() {
+			t.Errorf("Unexpected output: %q, expected %q", b.String(), expected.String())
+		}
+	}
+
+	if d := FromStdWriterConfig(stdcsv.NewWriter(nil)); d.Delimiter != ',' || d.LineTerminator != "\n" {
+		t.Errorf("Unexpected dialect: %#v", d)
+	}
+}
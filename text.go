@@ -0,0 +1,464 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Unescape interprets backslash escape sequences in s, so that users can type
+// characters such as tabs on the command line. Supported are \t, \n, \r, \a,
+// \b, \f, \v, \0, \\, \xHH, \uHHHH and \UHHHHHHHH. A backslash not starting
+// one of these is kept as is, which makes a single "\" mean a backslash.
+func Unescape(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'v':
+			b.WriteByte('\v')
+		case '0':
+			b.WriteByte(0)
+		case '\\':
+			b.WriteByte('\\')
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[i]]
+			if i+digits >= len(s) {
+				return "", fmt.Errorf("incomplete escape sequence in %q", s)
+			}
+			n, err := strconv.ParseUint(s[i+1:i+1+digits], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(n)) {
+				return "", fmt.Errorf("invalid escape sequence in %q", s)
+			}
+			b.WriteRune(rune(n))
+			i += digits
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// Escape is the inverse of Unescape. It escapes backslashes and control
+// characters, except for a single backslash which is kept as is.
+func Escape(s string) string {
+	if s == `\` {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			if r < 0x20 || r == 0x7F {
+				fmt.Fprintf(&b, `\x%02x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+// Names accepted for characters and line terminators in dialect specs, which
+// avoids having to escape them.
+var (
+	specChars = map[string]string{
+		"comma":     ",",
+		"tab":       "\t",
+		"space":     " ",
+		"pipe":      "|",
+		"semicolon": ";",
+		"colon":     ":",
+	}
+	specTerminators = map[string]string{
+		"lf":   "\n",
+		"crlf": "\r\n",
+		"cr":   "\r",
+		"any":  LineTerminatorAny,
+	}
+)
+
+// ParseDialect parses a compact dialect spec such as
+//
+//	delim=|,quote=",escape=\\,term=crlf
+//
+// made of comma separated key=value pairs. Values may use the escape
+// sequences of Unescape, and "\," for a comma. Characters can also be given
+// by name, such as "tab" and "comma", and line terminators as "lf", "crlf",
+// "cr" or "any". The spec may start with the name of a dialect registered
+// using RegisterDialect that the pairs following it then modify.
+//
+// Keys are delim, quote, closequote, escape, doublequote, quoting, term,
+// skipinitialspace, encoding, comment and header. An empty quote or escape
+// gives NoChar, as in "delim=tab,quote=,escape=".
+func ParseDialect(spec string) (Dialect, error) {
+	var d Dialect
+	for i, pair := range splitSpec(spec) {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			base, found := LookupDialect(pair)
+			if i > 0 || !found {
+				return Dialect{}, fmt.Errorf("csv: unknown dialect %q", pair)
+			}
+			d = base
+			continue
+		}
+		if err := d.setSpecValue(strings.ToLower(strings.TrimSpace(key)), value); err != nil {
+			return Dialect{}, err
+		}
+	}
+	if err := d.Validate(); err != nil {
+		return Dialect{}, err
+	}
+	return d, nil
+}
+
+// splitSpec splits spec at commas not escaped using a backslash. Escaped
+// commas are unescaped while other escape sequences are kept.
+func splitSpec(spec string) []string {
+	var pairs []string
+	var b strings.Builder
+	for i := 0; i < len(spec); i++ {
+		switch {
+		case spec[i] == '\\' && i+1 < len(spec):
+			if spec[i+1] != ',' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(spec[i+1])
+			i++
+		case spec[i] == ',':
+			pairs = append(pairs, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(spec[i])
+		}
+	}
+	if b.Len() > 0 || len(pairs) > 0 {
+		pairs = append(pairs, b.String())
+	}
+	return pairs
+}
+
+func (d *Dialect) setSpecValue(key, value string) error {
+	if name, ok := specChars[strings.ToLower(value)]; ok && key != "term" && key != "encoding" {
+		value = name
+	} else if name, ok := specTerminators[strings.ToLower(value)]; ok && key == "term" {
+		value = name
+	} else {
+		var err error
+		if value, err = Unescape(value); err != nil {
+			return fmt.Errorf("csv: %s: %v", key, err)
+		}
+	}
+
+	var err error
+	switch key {
+	case "delim", "delimiter":
+		d.SetDelimiter(value)
+	case "quote":
+		d.SetQuote(value)
+	case "closequote":
+		d.CloseQuoteString = value
+	case "escape":
+		d.EscapeChar, err = parseEscape(value)
+	case "doublequote":
+		d.DoubleQuote, err = ParseDoubleQuoteMode(value)
+	case "quoting":
+		d.Quoting, err = ParseQuoting(value)
+	case "term", "terminator":
+		d.LineTerminator = value
+	case "skipinitialspace":
+		d.SkipInitialSpace, err = strconv.ParseBool(value)
+	case "encoding":
+		d.Encoding = value
+	case "comment":
+		d.Comment, err = parseRune(value)
+	case "header":
+		d.Header, err = strconv.ParseBool(value)
+	default:
+		return fmt.Errorf("csv: unknown dialect key %q", key)
+	}
+	if err != nil {
+		return fmt.Errorf("csv: %s: %v", key, err)
+	}
+	return nil
+}
+
+// splitString returns s as a rune if it is a single one, and as a string
+// otherwise. Keeps dialects using single characters comparable with those
+// declared using Delimiter and QuoteChar.
+func splitString(s string) (rune, string) {
+	if utf8.RuneCountInString(s) == 1 {
+		r, _ := utf8.DecodeRuneInString(s)
+		return r, ""
+	}
+	return 0, s
+}
+
+// joinString is the inverse of splitString.
+func joinString(r rune, s string) string {
+	if s == "" {
+		return formatChar(r)
+	}
+	return s
+}
+
+// formatChar returns a character field of a Dialect as a string. Zero, for
+// unset, and NoChar give "".
+func formatChar(r rune) string {
+	if r == 0 || r == NoChar {
+		return ""
+	}
+	return string(r)
+}
+
+// splitQuote is like splitString, but an empty quote gives NoChar.
+func splitQuote(s string) (rune, string) {
+	if s == "" {
+		return NoChar, ""
+	}
+	return splitString(s)
+}
+
+// parseEscape is like parseRune, but an empty escape gives NoChar.
+func parseEscape(s string) (rune, error) {
+	if s == "" {
+		return NoChar, nil
+	}
+	return parseRune(s)
+}
+
+// parseRune parses a single character. An empty string gives zero.
+func parseRune(s string) (rune, error) {
+	switch utf8.RuneCountInString(s) {
+	case 0:
+		return 0, nil
+	case 1:
+		r, _ := utf8.DecodeRuneInString(s)
+		return r, nil
+	}
+	return 0, fmt.Errorf("%q is more than one character", s)
+}
+
+// String returns the dialect as a spec parseable by ParseDialect. Fields left
+// to their defaults are left out.
+func (d Dialect) String() string {
+	var pairs []string
+	add := func(key, value string) {
+		if value == `\` {
+			value = `\\`
+		} else {
+			value = Escape(value)
+		}
+		pairs = append(pairs, key+"="+strings.Replace(value, ",", `\,`, -1))
+	}
+	if s := joinString(d.Delimiter, d.DelimiterString); s != "" {
+		add("delim", s)
+	}
+	if s := joinString(d.QuoteChar, d.QuoteString); s != "" || d.QuoteChar == NoChar {
+		add("quote", s)
+	}
+	if d.CloseQuoteString != "" {
+		add("closequote", d.CloseQuoteString)
+	}
+	if s := formatChar(d.EscapeChar); s != "" || d.EscapeChar == NoChar {
+		add("escape", s)
+	}
+	if d.DoubleQuote != DoubleQuoteDefault {
+		add("doublequote", d.DoubleQuote.String())
+	}
+	if d.Quoting != QuoteDefault {
+		add("quoting", d.Quoting.String())
+	}
+	if d.LineTerminator != "" {
+		term := Escape(d.LineTerminator)
+		for name, t := range specTerminators {
+			if t == d.LineTerminator {
+				term = name
+			}
+		}
+		pairs = append(pairs, "term="+term)
+	}
+	if d.SkipInitialSpace {
+		add("skipinitialspace", "true")
+	}
+	if d.Encoding != "" {
+		add("encoding", d.Encoding)
+	}
+	if d.Comment != 0 {
+		add("comment", string(d.Comment))
+	}
+	if d.Header {
+		add("header", "true")
+	}
+	return strings.Join(pairs, ",")
+}
+
+// MarshalText implements encoding.TextMarshaler using the spec returned by
+// String. Dialects failing Validate are an error, since they couldn't be
+// parsed back.
+func (d Dialect) MarshalText() ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseDialect.
+func (d *Dialect) UnmarshalText(text []byte) error {
+	parsed, err := ParseDialect(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// dialectJSON is the JSON form of a Dialect.
+type dialectJSON struct {
+	Delimiter        string  `json:"delimiter,omitempty"`
+	Quote            *string `json:"quote,omitempty"`
+	CloseQuote       string  `json:"close_quote,omitempty"`
+	Escape           *string `json:"escape,omitempty"`
+	DoubleQuote      *bool   `json:"double_quote,omitempty"`
+	Quoting          string  `json:"quoting,omitempty"`
+	LineTerminator   string  `json:"line_terminator,omitempty"`
+	SkipInitialSpace bool    `json:"skip_initial_space,omitempty"`
+	Encoding         string  `json:"encoding,omitempty"`
+	Comment          string  `json:"comment,omitempty"`
+	Header           bool    `json:"header,omitempty"`
+}
+
+// jsonChar returns the JSON form of a quote or escape character: nil if
+// unset and "" for NoChar.
+func jsonChar(r rune, s string) *string {
+	if r == 0 && s == "" {
+		return nil
+	}
+	s = joinString(r, s)
+	return &s
+}
+
+// MarshalJSON implements json.Marshaler. Characters are written as strings
+// and quoting modes by name, such as {"delimiter": "\t", "quoting":
+// "minimal"}. Fields left to their defaults are left out. Dialects failing
+// Validate are an error, since they couldn't be unmarshaled.
+func (d Dialect) MarshalJSON() ([]byte, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	j := dialectJSON{
+		Delimiter:        joinString(d.Delimiter, d.DelimiterString),
+		Quote:            jsonChar(d.QuoteChar, d.QuoteString),
+		CloseQuote:       d.CloseQuoteString,
+		Escape:           jsonChar(d.EscapeChar, ""),
+		LineTerminator:   d.LineTerminator,
+		SkipInitialSpace: d.SkipInitialSpace,
+		Encoding:         d.Encoding,
+		Comment:          formatChar(d.Comment),
+		Header:           d.Header,
+	}
+	if d.DoubleQuote != DoubleQuoteDefault {
+		double := d.DoubleQuote == DoDoubleQuote
+		j.DoubleQuote = &double
+	}
+	if d.Quoting != QuoteDefault {
+		j.Quoting = d.Quoting.String()
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Besides the object written by
+// MarshalJSON, a string holding a spec parseable by ParseDialect is accepted.
+// Like for other types, null leaves d unchanged.
+func (d *Dialect) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var spec string
+		if err := json.Unmarshal(data, &spec); err != nil {
+			return err
+		}
+		return d.UnmarshalText([]byte(spec))
+	}
+
+	var j dialectJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&j); err != nil {
+		return err
+	}
+
+	parsed := Dialect{
+		CloseQuoteString: j.CloseQuote,
+		LineTerminator:   j.LineTerminator,
+		SkipInitialSpace: j.SkipInitialSpace,
+		Encoding:         j.Encoding,
+		Header:           j.Header,
+	}
+	parsed.SetDelimiter(j.Delimiter)
+	var err error
+	if j.Quote != nil {
+		parsed.SetQuote(*j.Quote)
+	}
+	if j.Escape != nil {
+		if parsed.EscapeChar, err = parseEscape(*j.Escape); err != nil {
+			return fmt.Errorf("csv: escape: %v", err)
+		}
+	}
+	if parsed.Comment, err = parseRune(j.Comment); err != nil {
+		return fmt.Errorf("csv: comment: %v", err)
+	}
+	if j.Quoting != "" {
+		if parsed.Quoting, err = ParseQuoting(j.Quoting); err != nil {
+			return err
+		}
+	}
+	if j.DoubleQuote != nil {
+		parsed.DoubleQuote = NoDoubleQuote
+		if *j.DoubleQuote {
+			parsed.DoubleQuote = DoDoubleQuote
+		}
+	}
+	if err := parsed.Validate(); err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
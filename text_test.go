@@ -0,0 +1,173 @@
+// Copyright 2014 Jens Rantil. All rights reserved.  Use of this source code is
+// governed by a BSD-style license that can be found in the LICENSE file.
+
+package csv
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestUnescape(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"":             "",
+		`\`:            `\`,
+		`\\`:           `\`,
+		`\t`:           "\t",
+		`a\r\nb`:       "a\r\nb",
+		`\x1fé`:        "\x1fé",
+		`\U0001F600\q`: "😀\\q",
+	}
+	for s, expected := range tests {
+		if u, err := Unescape(s); err != nil || u != expected {
+			t.Errorf("Unexpected result for %q: %q, %v", s, u, err)
+		}
+	}
+}
+
+func TestFormatChar(t *testing.T) {
+	t.Parallel()
+
+	tests := map[rune]string{
+		0:      "",
+		NoChar: "",
+		'\t':   "\t",
+		'é':    "é",
+	}
+	for r, expected := range tests {
+		if s := formatChar(r); s != expected {
+			t.Errorf("Unexpected result for %q: %q", r, s)
+		}
+	}
+}
+
+func TestParseDialect(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]Dialect{
+		"": {},
+		`delim=|,quote=",escape=\\,term=crlf`: {
+			Delimiter:      '|',
+			QuoteChar:      '"',
+			EscapeChar:     '\\',
+			LineTerminator: "\r\n",
+		},
+		`delim=tab,quoting=nonnumeric,doublequote=false,comment=#,header=true`: {
+			Delimiter:   '\t',
+			Quoting:     QuoteNonNumeric,
+			DoubleQuote: NoDoubleQuote,
+			Comment:     '#',
+			Header:      true,
+		},
+		`delim=\,,quote=<<,closequote=>>,term=any,encoding=utf-16le,skipinitialspace=1`: {
+			Delimiter:        ',',
+			QuoteString:      "<<",
+			CloseQuoteString: ">>",
+			LineTerminator:   LineTerminatorAny,
+			Encoding:         EncodingUTF16LE,
+			SkipInitialSpace: true,
+		},
+		`delim=tab,quote=,escape=`: {
+			Delimiter:  '\t',
+			QuoteChar:  NoChar,
+			EscapeChar: NoChar,
+		},
+		`excel,delim=;,term=lf`: {
+			Delimiter:      ';',
+			QuoteChar:      '"',
+			DoubleQuote:    DoDoubleQuote,
+			Quoting:        QuoteMinimal,
+			LineTerminator: "\n",
+		},
+	}
+	for spec, expected := range tests {
+		d, err := ParseDialect(spec)
+		if err != nil {
+			t.Error("Unexpected error:", spec, err)
+		}
+		if !reflect.DeepEqual(d, expected) {
+			t.Errorf("Unexpected dialect for %q: %#v", spec, d)
+		}
+
+		// Round trips using the text form.
+		var parsed Dialect
+		if err := parsed.UnmarshalText([]byte(d.String())); err != nil || !reflect.DeepEqual(parsed, d) {
+			t.Errorf("Unexpected round trip of %q: %#v, %v", d.String(), parsed, err)
+		}
+	}
+
+	for _, spec := range []string{"delim", "unknown=1", "escape=ab", "quoting=sometimes", "delim=;,excel", "header=maybe"} {
+		if _, err := ParseDialect(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestDialectJSON(t *testing.T) {
+	t.Parallel()
+
+	d := Dialect{
+		Delimiter:      '\t',
+		QuoteString:    "<<",
+		DoubleQuote:    NoDoubleQuote,
+		Quoting:        QuoteMinimal,
+		LineTerminator: "\r\n",
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	expected := `{"delimiter":"\t","quote":"\u003c\u003c","double_quote":false,"quoting":"minimal","line_terminator":"\r\n"}`
+	if string(b) != expected {
+		t.Error("Unexpected JSON:", string(b))
+	}
+
+	var parsed Dialect
+	if err := json.Unmarshal(b, &parsed); err != nil || !reflect.DeepEqual(parsed, d) {
+		t.Errorf("Unexpected dialect: %#v, %v", parsed, err)
+	}
+
+	// An empty quote or escape means NoChar.
+	d = Dialect{Delimiter: '\t', QuoteChar: NoChar, EscapeChar: NoChar}
+	if b, err = json.Marshal(d); err != nil || string(b) != `{"delimiter":"\t","quote":"","escape":""}` {
+		t.Error("Unexpected JSON:", string(b), err)
+	}
+	parsed = Dialect{}
+	if err := json.Unmarshal(b, &parsed); err != nil || !reflect.DeepEqual(parsed, d) {
+		t.Errorf("Unexpected dialect: %#v, %v", parsed, err)
+	}
+
+	// Specs are accepted as well.
+	var config struct{ Input Dialect }
+	if err := json.Unmarshal([]byte(`{"Input": "delim=|,term=crlf"}`), &config); err != nil {
+		t.Error("Unexpected error:", err)
+	}
+	if config.Input.Delimiter != '|' || config.Input.LineTerminator != "\r\n" {
+		t.Errorf("Unexpected dialect: %#v", config.Input)
+	}
+
+	for _, s := range []string{`{"delimiter":1}`, `{"quoting":"sometimes"}`, `{"escape":"ab"}`, `{"delimter":","}`} {
+		if err := json.Unmarshal([]byte(s), &parsed); err == nil {
+			t.Errorf("Expected error for %s", s)
+		}
+	}
+
+	// Null leaves the dialect as it was.
+	if err := json.Unmarshal([]byte(`{"Input": null}`), &config); err != nil || config.Input.Delimiter != '|' {
+		t.Errorf("Unexpected dialect: %#v, %v", config.Input, err)
+	}
+
+	// What couldn't be read back can't be written.
+	for _, d := range []Dialect{{Quoting: 42}, {DoubleQuote: 42}} {
+		if _, err := json.Marshal(d); !errors.Is(err, ErrInvalidDialect) {
+			t.Errorf("Unexpected error for %#v: %v", d, err)
+		}
+		if _, err := d.MarshalText(); !errors.Is(err, ErrInvalidDialect) {
+			t.Errorf("Unexpected error for %#v: %v", d, err)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"bufio"
 	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 // A Writer writes records to a CSV encoded file.
@@ -15,6 +16,11 @@ import (
 type Writer struct {
 	opts Dialect
 	w    *bufio.Writer
+
+	// Quote fields containing "\r" or "\n" whatever the line terminator, and
+	// write "\n" within them as "\r\n" and drop "\r" if the line terminator is
+	// "\r\n", like encoding/csv. Set by StdWriter.
+	stdLineBreaks bool
 }
 
 // Create a writer that conforms to RFC 4180 and behaves identical as a
@@ -35,10 +41,15 @@ func NewDialectWriter(w io.Writer, opts Dialect) Writer {
 	opts.setDefaults()
 	return Writer{
 		opts: opts,
-		w:    bufio.NewWriter(w),
+		w:    bufio.NewWriter(newEncoder(w, opts.Encoding)),
 	}
 }
 
+// Dialect returns the dialect of w, with defaults applied.
+func (w Writer) Dialect() Dialect {
+	return w.opts
+}
+
 // Error reports any error that has occurred during a previous Write or Flush.
 func (w Writer) Error() error {
 	_, err := w.w.Write(nil)
@@ -59,10 +70,14 @@ func (w Writer) writeString(s string) error {
 }
 
 func (w Writer) writeDelimiter() error {
-	return w.writeRune(w.opts.Delimiter)
+	return w.writeString(w.opts.DelimiterString)
 }
 
 func (w Writer) fieldNeedsQuote(field string) bool {
+	if w.opts.QuoteString == "" {
+		// No quote character to quote with.
+		return false
+	}
 	switch w.opts.Quoting {
 	case QuoteNone:
 		return false
@@ -73,7 +88,13 @@ func (w Writer) fieldNeedsQuote(field string) bool {
 	case QuoteMinimal:
 		// TODO: Can be improved by making a single search with trie.
 		// See https://docs.python.org/2/library/csv.html#csv.QUOTE_MINIMAL for info on this.
-		return strings.Contains(field, w.opts.LineTerminator) || strings.ContainsRune(field, w.opts.Delimiter) || strings.ContainsRune(field, w.opts.QuoteChar)
+		if (w.opts.LineTerminator == LineTerminatorAny || w.stdLineBreaks) && strings.ContainsAny(field, "\r\n") {
+			return true
+		}
+		return strings.Contains(field, w.opts.LineTerminator) ||
+			strings.Contains(field, w.opts.DelimiterString) ||
+			strings.Contains(field, w.opts.QuoteString) ||
+			strings.Contains(field, w.opts.CloseQuoteString)
 	}
 	panic("Unexpected quoting.")
 }
@@ -83,40 +104,46 @@ func (w Writer) writeRune(r rune) error {
 	return err
 }
 
-func (w Writer) writeEscapeChar(r rune) error {
+// writeEscape writes whatever is needed in front of s to escape it within a
+// quoted field. s is written again when using double quoting.
+func (w Writer) writeEscape(s string) error {
 	switch w.opts.DoubleQuote {
 	case DoDoubleQuote:
-		return w.writeRune(r)
+		return w.writeString(s)
 	case NoDoubleQuote:
 		return w.writeRune(w.opts.EscapeChar)
 	}
 	panic("Unrecognized double quote type.")
 }
 
-func (w Writer) writeQuotedRune(r rune) error {
-	switch r {
-	case w.opts.EscapeChar:
-		if err := w.writeEscapeChar(r); err != nil {
-			return err
-		}
-	case w.opts.QuoteChar:
-		if err := w.writeEscapeChar(r); err != nil {
-			return err
-		}
-	}
-	return w.writeRune(r)
-}
-
 func (w Writer) writeQuoted(field string) error {
-	if err := w.writeRune(w.opts.QuoteChar); err != nil {
+	if err := w.writeString(w.opts.QuoteString); err != nil {
 		return err
 	}
-	for _, r := range field {
-		if err := w.writeQuotedRune(r); err != nil {
+	crlf := w.stdLineBreaks && w.opts.LineTerminator == "\r\n"
+	for len(field) > 0 {
+		r, size := utf8.DecodeRuneInString(field)
+		s := field[:size]
+		out := s
+		escape := r == w.opts.EscapeChar
+		if strings.HasPrefix(field, w.opts.CloseQuoteString) {
+			s, out, escape = w.opts.CloseQuoteString, w.opts.CloseQuoteString, true
+		} else if crlf && r == '\r' {
+			out = ""
+		} else if crlf && r == '\n' {
+			out = "\r\n"
+		}
+		if escape {
+			if err := w.writeEscape(s); err != nil {
+				return err
+			}
+		}
+		if err := w.writeString(out); err != nil {
 			return err
 		}
+		field = field[len(s):]
 	}
-	return w.writeRune(w.opts.QuoteChar)
+	return w.writeString(w.opts.CloseQuoteString)
 }
 
 func (w Writer) writeField(field string) error {
@@ -127,6 +154,9 @@ func (w Writer) writeField(field string) error {
 }
 
 func (w Writer) writeNewline() error {
+	if w.opts.LineTerminator == LineTerminatorAny {
+		return w.writeString(DefaultLineTerminator)
+	}
 	return w.writeString(w.opts.LineTerminator)
 }
 
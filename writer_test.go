@@ -5,12 +5,13 @@ package csv
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 	"testing/quick"
 )
 
 // Execute a quicktest for a specific quoting.
-func testWriterQuick(t *testing.T, quoting int) {
+func testWriterQuick(t *testing.T, quoting Quoting) {
 	f := func(records [][]string, doubleQuote bool, escapeChar, del, quoteChar rune, lt string) bool {
 		b1 := new(bytes.Buffer)
 		dialect := Dialect{
@@ -167,3 +168,50 @@ func TestNewLineRecord(t *testing.T) {
 		t.Error("Unexpected output:", s)
 	}
 }
+
+func TestWritingMultiCharDelimiterAndQuotes(t *testing.T) {
+	t.Parallel()
+
+	b := new(bytes.Buffer)
+	w := NewDialectWriter(b, Dialect{
+		DelimiterString:  "~|~",
+		QuoteString:      "<<",
+		CloseQuoteString: ">>",
+	})
+	w.Write([]string{"a", "b~|~c", "d>>e", "f<<"})
+	w.Flush()
+	if s := b.String(); s != "a~|~<<b~|~c>>~|~<<d>>>>e>>~|~<<f<<>>\n" {
+		t.Error("Unexpected output:", s)
+	}
+}
+
+func TestWritingWithoutQuotes(t *testing.T) {
+	t.Parallel()
+
+	b := new(bytes.Buffer)
+	w := NewDialectWriter(b, Dialect{Delimiter: '\t', QuoteChar: NoChar, EscapeChar: NoChar})
+	w.Write([]string{`"a"`, `b\`, "c d"})
+	w.Flush()
+	if s := b.String(); s != "\"a\"\tb\\\tc d\n" {
+		t.Errorf("Unexpected output: %q", s)
+	}
+}
+
+func TestWritingEncoding(t *testing.T) {
+	t.Parallel()
+
+	b := new(bytes.Buffer)
+	w := NewDialectWriter(b, Dialect{Delimiter: ',', Encoding: EncodingWindows1252})
+	w.Write([]string{"a", "é€"})
+	w.Flush()
+	if s := b.String(); s != "a,\xe9\x80\n" {
+		t.Errorf("Unexpected output: %q", s)
+	}
+
+	w = NewDialectWriter(new(bytes.Buffer), Dialect{Encoding: EncodingLatin1})
+	w.Write([]string{"€"})
+	w.Flush()
+	if err := w.Error(); !errors.Is(err, ErrUnencodable) {
+		t.Error("Unexpected error:", err)
+	}
+}